@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"main/internal/adapters/database/psql"
+	"main/internal/config"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+// migrateCommand is the name of the subcommand managing the PostgreSQL schema.
+const migrateCommand = "migrate"
+
+// runMigrate applies or rolls back PostgreSQL schema migrations.
+//
+// Usage:
+//
+//	shortener [flags] migrate up          | Apply all pending migrations.
+//	shortener [flags] migrate down [N]    | Roll back the last N applied migrations (1 by default).
+func runMigrate(c *config.Config, args []string, logger *zap.SugaredLogger) error {
+	if c.PostgresDSN == nil {
+		return errors.New("database DSN is not configured")
+	}
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | migrate down [N]")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer stop()
+
+	db, err := psql.OpenPostgresDB(c.PostgresDSN, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := psql.MigrateUp(ctx, db)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Applied migrations: %v\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations to roll back: %q", args[1])
+			}
+		}
+		reverted, err := psql.MigrateDown(ctx, db, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Reverted migrations: %v\n", reverted)
+	default:
+		return fmt.Errorf("unknown migrate direction %q, expected up or down", args[0])
+	}
+	return nil
+}
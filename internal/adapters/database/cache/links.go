@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"main/internal/constants"
+	"main/internal/interfaces"
+	"main/internal/models"
+	"main/internal/services"
+	"time"
+)
+
+// Metrics of the redirect cache, published at /debug/vars of the pprof server.
+var (
+	metrics   = expvar.NewMap("links_cache")
+	hits      = new(expvar.Int) // Lookups answered from fresh entries, including negative ones.
+	misses    = new(expvar.Int) // Lookups passed to the repository.
+	staleHits = new(expvar.Int) // Lookups answered from stale entries because the repository failed.
+	evictions = new(expvar.Int) // Entries evicted to make room for new ones.
+)
+
+func init() {
+	metrics.Set("hits", hits)
+	metrics.Set("misses", misses)
+	metrics.Set("stale_hits", staleHits)
+	metrics.Set("evictions", evictions)
+	metrics.Set("hit_ratio", expvar.Func(hitRatio))
+}
+
+// hitRatio returns the share of lookups answered from the cache.
+func hitRatio() any {
+	served := hits.Value() + staleHits.Value()
+	total := served + misses.Value()
+	if total == 0 {
+		return 0.0
+	}
+	return float64(served) / float64(total)
+}
+
+// LinksRepository is a read-through cache of redirect lookups in front of another links repository.
+// Found links are kept for the configured TTL and unknown, deleted or expired ones for a shorter time.
+// If a lookup fails, for example because the database is briefly unreachable, a stale entry is served instead.
+// Entries are invalidated when links are added, updated, deleted or restored through this instance;
+// changes made through other instances become visible once the entries go stale, and so do links
+// that expire while cached.
+type LinksRepository struct {
+	interfaces.LinksRepository               // Repository the lookups are passed to.
+	cache                      *lru          // Cached lookup results.
+	ttl                        time.Duration // Time found links are served from the cache.
+}
+
+// NewLinksRepository wraps a links repository with a cache holding up to size links for the given TTL.
+func NewLinksRepository(next interfaces.LinksRepository, size int, ttl time.Duration) *LinksRepository {
+	return &LinksRepository{
+		LinksRepository: next,
+		cache:           newLRU(size),
+		ttl:             ttl,
+	}
+}
+
+// Get resolves a short link from the cache, looking it up in the repository when the entry is missing or stale.
+// The result is not cached if the link was invalidated during the lookup, as it may predate the change.
+func (r *LinksRepository) Get(ctx context.Context, short string) (string, error) {
+	now := time.Now()
+
+	cached, ok := r.cache.get(short)
+	if ok && now.Before(cached.freshUntil) {
+		hits.Add(1)
+		return cached.origin, cached.err
+	}
+	misses.Add(1)
+
+	generation := r.cache.generation(short)
+	origin, err := r.LinksRepository.Get(ctx, short)
+	switch {
+	case err == nil:
+		r.put(entry{short: short, origin: origin, freshUntil: now.Add(r.ttl), staleUntil: now.Add(r.ttl + constants.LinksCacheStaleTime)}, generation)
+	case definitive(err):
+		r.put(entry{short: short, err: err, freshUntil: now.Add(min(r.ttl, constants.LinksCacheNegativeTTL))}, generation)
+	case ok && cached.err == nil && now.Before(cached.staleUntil):
+		staleHits.Add(1)
+		return cached.origin, nil
+	}
+	return origin, err
+}
+
+// Add creates a link and drops a cached miss of its short link.
+func (r *LinksRepository) Add(ctx context.Context, addedLink models.AddedLink) (string, error) {
+	defer r.cache.remove(addedLink.Short)
+	return r.LinksRepository.Add(ctx, addedLink)
+}
+
+// AddBatch creates links and drops cached misses of their short links.
+func (r *LinksRepository) AddBatch(ctx context.Context, addedLinks []models.AddedLink) ([]models.Result, error) {
+	defer r.removeAdded(addedLinks)
+	return r.LinksRepository.AddBatch(ctx, addedLinks)
+}
+
+// Import adds imported links and drops cached misses of their short links.
+func (r *LinksRepository) Import(ctx context.Context, addedLinks []models.AddedLink) ([]models.Result, error) {
+	defer r.removeAdded(addedLinks)
+	return r.LinksRepository.Import(ctx, addedLinks)
+}
+
+// Update changes the destination of a link and drops its cached lookup.
+func (r *LinksRepository) Update(ctx context.Context, short, origin string) error {
+	defer r.cache.remove(short)
+	return r.LinksRepository.Update(ctx, short, origin)
+}
+
+// DeleteExpired removes expired links and clears the cache if any were removed.
+func (r *LinksRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := r.LinksRepository.DeleteExpired(ctx, before)
+	if deleted > 0 {
+		r.cache.clear()
+	}
+	return deleted, err
+}
+
+// DeleteSoftDeleted purges soft-deleted links and clears the cache if any were purged.
+func (r *LinksRepository) DeleteSoftDeleted(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := r.LinksRepository.DeleteSoftDeleted(ctx, before)
+	if purged > 0 {
+		r.cache.clear()
+	}
+	return purged, err
+}
+
+// put stores a lookup result unless its link was invalidated since the generation was taken, counting evicted entries.
+func (r *LinksRepository) put(e entry, generation uint64) {
+	evictions.Add(int64(r.cache.putIfCurrent(e, generation)))
+}
+
+// removeAdded drops the cached lookups of added links.
+func (r *LinksRepository) removeAdded(addedLinks []models.AddedLink) {
+	shorts := make([]string, len(addedLinks))
+	for i, addedLink := range addedLinks {
+		shorts[i] = addedLink.Short
+	}
+	r.cache.remove(shorts...)
+}
+
+// definitive reports whether a lookup error describes the link itself rather than a failure to look it up.
+func definitive(err error) bool {
+	return errors.Is(err, services.ErrLinkNotFound) || errors.Is(err, services.ErrDeletedLink) || errors.Is(err, services.ErrExpiredLink)
+}
+
+// UsersRepository passes user operations to another users repository,
+// dropping the cached lookups of links the users delete or restore.
+type UsersRepository struct {
+	interfaces.UsersRepository                  // Repository the operations are passed to.
+	links                      *LinksRepository // Cache of the lookups to invalidate.
+}
+
+// NewUsersRepository wraps a users repository so that it invalidates the given links cache.
+func NewUsersRepository(next interfaces.UsersRepository, links *LinksRepository) *UsersRepository {
+	return &UsersRepository{
+		UsersRepository: next,
+		links:           links,
+	}
+}
+
+// DeleteLinks soft-deletes links and drops their cached lookups.
+func (r *UsersRepository) DeleteLinks(ctx context.Context, deletions []models.LinkDeletion) error {
+	defer func() {
+		for _, deletion := range deletions {
+			r.links.cache.remove(deletion.Short)
+		}
+	}()
+	return r.UsersRepository.DeleteLinks(ctx, deletions)
+}
+
+// RestoreLinks restores soft-deleted links and drops their cached lookups.
+func (r *UsersRepository) RestoreLinks(ctx context.Context, shortLinks []string) (int64, error) {
+	defer r.links.cache.remove(shortLinks...)
+	return r.UsersRepository.RestoreLinks(ctx, shortLinks)
+}
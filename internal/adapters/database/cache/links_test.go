@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"main/internal/mocks"
+	"main/internal/models"
+	"main/internal/services"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinksRepositoryGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	next := mocks.NewMockLinksRepository(ctrl)
+	repo := NewLinksRepository(next, 10, time.Minute)
+
+	next.EXPECT().Get(gomock.Any(), "found").Return("https://example.com/", nil).Times(1)
+	next.EXPECT().Get(gomock.Any(), "missing").Return("", services.ErrLinkNotFound).Times(1)
+	next.EXPECT().Get(gomock.Any(), "broken").Return("", errors.New("connection refused")).Times(2)
+
+	hitsBefore := hits.Value()
+	for i := 0; i < 3; i++ {
+		origin, err := repo.Get(ctx, "found")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/", origin)
+
+		_, err = repo.Get(ctx, "missing")
+		assert.ErrorIs(t, err, services.ErrLinkNotFound, "misses are cached")
+	}
+	assert.Equal(t, int64(4), hits.Value()-hitsBefore)
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.Get(ctx, "broken")
+		assert.Error(t, err, "failures are not cached")
+	}
+}
+
+func TestLinksRepositoryStaleOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	next := mocks.NewMockLinksRepository(ctrl)
+	repo := NewLinksRepository(next, 10, time.Minute)
+
+	next.EXPECT().Get(gomock.Any(), "short").Return("https://example.com/", nil)
+	_, err := repo.Get(ctx, "short")
+	require.NoError(t, err)
+
+	// Make the entry stale.
+	cached, _ := repo.cache.get("short")
+	cached.freshUntil = time.Now().Add(-time.Second)
+	repo.cache.put(cached)
+
+	next.EXPECT().Get(gomock.Any(), "short").Return("", context.DeadlineExceeded)
+	origin, err := repo.Get(ctx, "short")
+	require.NoError(t, err, "stale entries are served while the repository fails")
+	assert.Equal(t, "https://example.com/", origin)
+
+	next.EXPECT().Get(gomock.Any(), "short").Return("", services.ErrDeletedLink)
+	_, err = repo.Get(ctx, "short")
+	assert.ErrorIs(t, err, services.ErrDeletedLink, "definitive answers replace stale entries")
+
+	_, err = repo.Get(ctx, "short")
+	assert.ErrorIs(t, err, services.ErrDeletedLink)
+}
+
+func TestLinksRepositoryEviction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	next := mocks.NewMockLinksRepository(ctrl)
+	repo := NewLinksRepository(next, 2, time.Minute)
+
+	next.EXPECT().Get(gomock.Any(), "a").Return("https://a.example/", nil).Times(2)
+	next.EXPECT().Get(gomock.Any(), "b").Return("https://b.example/", nil).Times(1)
+	next.EXPECT().Get(gomock.Any(), "c").Return("https://c.example/", nil).Times(1)
+
+	for _, short := range []string{"a", "b", "b", "c", "b", "a"} {
+		_, err := repo.Get(ctx, short)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, repo.cache.len())
+}
+
+func TestLinksRepositoryInvalidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	next := mocks.NewMockLinksRepository(ctrl)
+	users := mocks.NewMockUsersRepository(ctrl)
+	repo := NewLinksRepository(next, 10, time.Minute)
+	usersRepo := NewUsersRepository(users, repo)
+
+	next.EXPECT().Get(gomock.Any(), "alias").Return("", services.ErrLinkNotFound)
+	next.EXPECT().Add(gomock.Any(), gomock.Any()).Return("alias", nil)
+	next.EXPECT().Get(gomock.Any(), "alias").Return("https://old.example/", nil)
+	next.EXPECT().Update(gomock.Any(), "alias", "https://new.example/").Return(nil)
+	next.EXPECT().Get(gomock.Any(), "alias").Return("https://new.example/", nil)
+	users.EXPECT().DeleteLinks(gomock.Any(), gomock.Any()).Return(nil)
+	next.EXPECT().Get(gomock.Any(), "alias").Return("", services.ErrDeletedLink)
+	users.EXPECT().RestoreLinks(gomock.Any(), []string{"alias"}).Return(int64(1), nil)
+	next.EXPECT().Get(gomock.Any(), "alias").Return("https://new.example/", nil)
+
+	_, err := repo.Get(ctx, "alias")
+	assert.ErrorIs(t, err, services.ErrLinkNotFound)
+
+	_, err = repo.Add(ctx, models.AddedLink{Short: "alias", Origin: "https://old.example/"})
+	require.NoError(t, err)
+	origin, err := repo.Get(ctx, "alias")
+	require.NoError(t, err)
+	assert.Equal(t, "https://old.example/", origin)
+
+	require.NoError(t, repo.Update(ctx, "alias", "https://new.example/"))
+	origin, err = repo.Get(ctx, "alias")
+	require.NoError(t, err)
+	assert.Equal(t, "https://new.example/", origin)
+
+	require.NoError(t, usersRepo.DeleteLinks(ctx, []models.LinkDeletion{{Short: "alias", UserID: 1}}))
+	_, err = repo.Get(ctx, "alias")
+	assert.ErrorIs(t, err, services.ErrDeletedLink)
+
+	_, err = usersRepo.RestoreLinks(ctx, []string{"alias"})
+	require.NoError(t, err)
+	origin, err = repo.Get(ctx, "alias")
+	require.NoError(t, err)
+	assert.Equal(t, "https://new.example/", origin)
+}
+
+func TestLinksRepositoryInvalidationDuringLookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	next := mocks.NewMockLinksRepository(ctrl)
+	repo := NewLinksRepository(next, 10, time.Minute)
+
+	// The update lands while the lookup of the previous destination is in flight.
+	next.EXPECT().Update(gomock.Any(), "alias", "https://new.example/").Return(nil)
+	next.EXPECT().Get(gomock.Any(), "alias").DoAndReturn(func(ctx context.Context, short string) (string, error) {
+		require.NoError(t, repo.Update(ctx, short, "https://new.example/"))
+		return "https://old.example/", nil
+	})
+	next.EXPECT().Get(gomock.Any(), "alias").Return("https://new.example/", nil)
+
+	origin, err := repo.Get(ctx, "alias")
+	require.NoError(t, err)
+	assert.Equal(t, "https://old.example/", origin)
+
+	origin, err = repo.Get(ctx, "alias")
+	require.NoError(t, err)
+	assert.Equal(t, "https://new.example/", origin, "results looked up before an invalidation are not cached")
+
+	// Clearing the cache invalidates lookups in flight as well.
+	next.EXPECT().Get(gomock.Any(), "other").DoAndReturn(func(ctx context.Context, short string) (string, error) {
+		repo.cache.clear()
+		return "https://other.example/", nil
+	})
+	_, err = repo.Get(ctx, "other")
+	require.NoError(t, err)
+	assert.Zero(t, repo.cache.len())
+}
@@ -0,0 +1,146 @@
+package cache // Package cache provides caching decorators for the repositories.
+
+import (
+	"container/list"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+// generationStripes is the number of invalidation counters short links are spread over.
+const generationStripes = 256
+
+// entry is a cached result of a link lookup.
+type entry struct {
+	short      string    // Short link the entry belongs to.
+	origin     string    // Original URL, empty for negative entries.
+	err        error     // Definitive lookup error of negative entries, such as a missing or deleted link.
+	freshUntil time.Time // Moment after which the link has to be looked up again.
+	staleUntil time.Time // Moment until which the entry may still be served if the lookup fails.
+}
+
+// lru is a size-bounded cache of lookup results that evicts the least recently used entries first.
+// Invalidations are counted per short link, so that results looked up before an invalidation
+// are not stored after it. The counters are shared by short links hashing to the same stripe,
+// which at worst leaves such a result uncached. It is safe for concurrent use.
+type lru struct {
+	mu          sync.Mutex                // Guards the list, the index and the counters.
+	size        int                       // Maximum number of entries.
+	order       *list.List                // Entries from the most to the least recently used.
+	entries     map[string]*list.Element  // Elements of the order list by short link.
+	seed        maphash.Seed              // Seed spreading short links over the stripes.
+	epoch       uint64                    // Number of times the whole cache was cleared.
+	generations [generationStripes]uint64 // Number of invalidations per stripe of short links.
+}
+
+// newLRU creates an empty cache holding up to size entries.
+func newLRU(size int) *lru {
+	return &lru{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+		seed:    maphash.MakeSeed(),
+	}
+}
+
+// generation returns the invalidation counter of a short link, which changes whenever its entry is invalidated.
+func (c *lru) generation(short string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generationLocked(short)
+}
+
+// generationLocked returns the invalidation counter of a short link; c.mu must be held.
+func (c *lru) generationLocked(short string) uint64 {
+	return c.epoch + c.generations[c.stripe(short)]
+}
+
+// stripe returns the index of the invalidation counter of a short link.
+func (c *lru) stripe(short string) int {
+	return int(maphash.String(c.seed, short) % generationStripes)
+}
+
+// get returns the entry of a short link and marks it as recently used.
+func (c *lru) get(short string) (entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[short]
+	if !ok {
+		return entry{}, false
+	}
+	c.order.MoveToFront(elem)
+	return *elem.Value.(*entry), true
+}
+
+// put stores the entry of a short link, evicting the least recently used entries beyond the size,
+// and returns how many were evicted.
+func (c *lru) put(e entry) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.putLocked(e)
+}
+
+// putIfCurrent stores the entry like put unless the short link was invalidated since its generation was taken.
+func (c *lru) putIfCurrent(e entry, generation uint64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generationLocked(e.short) != generation {
+		return 0
+	}
+	return c.putLocked(e)
+}
+
+// putLocked stores the entry of a short link and returns how many entries were evicted; c.mu must be held.
+func (c *lru) putLocked(e entry) int {
+	if elem, ok := c.entries[e.short]; ok {
+		*elem.Value.(*entry) = e
+		c.order.MoveToFront(elem)
+		return 0
+	}
+	c.entries[e.short] = c.order.PushFront(&e)
+
+	evicted := 0
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).short)
+		evicted++
+	}
+	return evicted
+}
+
+// remove drops the entries of the given short links and advances their invalidation counters.
+func (c *lru) remove(shorts ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, short := range shorts {
+		c.generations[c.stripe(short)]++
+		if elem, ok := c.entries[short]; ok {
+			c.order.Remove(elem)
+			delete(c.entries, short)
+		}
+	}
+}
+
+// clear drops all entries and advances every invalidation counter.
+func (c *lru) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	c.order.Init()
+	clear(c.entries)
+}
+
+// len returns the number of entries.
+func (c *lru) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"main/internal/models"
+	"main/internal/services"
+	"sort"
+	"time"
+)
+
+// ClicksRepository manages click events kept in the in-memory database.
+type ClicksRepository struct {
+	db *InMemoryDB // Pointer to the in-memory database instance.
+}
+
+// NewClicksRepository creates a new instance of ClicksRepository bound to a specific InMemoryDB.
+func NewClicksRepository(db *InMemoryDB) *ClicksRepository {
+	return &ClicksRepository{
+		db: db,
+	}
+}
+
+// AddBatch stores multiple clicks at once and persists them as a single event.
+func (r *ClicksRepository) AddBatch(ctx context.Context, clicks []models.Click) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		if len(clicks) == 0 {
+			return nil
+		}
+		stored := make([]models.StoredClick, len(clicks))
+		for i, click := range clicks {
+			stored[i] = models.StoredClick(click)
+		}
+
+		r.db.clicksMu.Lock()
+		defer r.db.clicksMu.Unlock()
+
+		event := &models.Event{
+			ID:     r.db.nextEventID(),
+			Type:   models.ClicksAddedEvent,
+			Clicks: stored,
+		}
+		if err := r.db.producerFS.WriteEvent(event); err != nil {
+			return err
+		}
+		r.db.addClicks(clicks)
+		return nil
+	}
+}
+
+// GetStats aggregates the clicks of a short link into a total and per-day buckets.
+func (r *ClicksRepository) GetStats(ctx context.Context, short string) (models.LinkStats, error) {
+	select {
+	case <-ctx.Done():
+		return models.LinkStats{}, ctx.Err()
+	default:
+		l, ok := r.db.links.get(short)
+		if !ok || l.userID != userIDFromContext(ctx) {
+			return models.LinkStats{}, services.ErrLinkNotFound
+		}
+
+		r.db.clicksMu.RLock()
+		defer r.db.clicksMu.RUnlock()
+
+		stats := models.LinkStats{
+			Short: short,
+		}
+		daily := make(map[time.Time]int64)
+		for _, click := range r.db.clicks[short] {
+			day := click.ClickedAt.UTC().Truncate(24 * time.Hour)
+			daily[day]++
+			stats.Total++
+		}
+		for day, clicks := range daily {
+			stats.Daily = append(stats.Daily, models.DailyClicks{Day: day, Clicks: clicks})
+		}
+		sort.Slice(stats.Daily, func(i, j int) bool {
+			return stats.Daily[i].Day.Before(stats.Daily[j].Day)
+		})
+		return stats, nil
+	}
+}
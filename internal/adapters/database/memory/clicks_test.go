@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"main/internal/adapters"
+	"main/internal/constants"
+	"main/internal/models"
+	"main/internal/services"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClicksRepository(t *testing.T) {
+	logger := adapters.GetLogger()
+	path := filepath.Join(t.TempDir(), "clicks.jsonl")
+	ownerCtx := context.WithValue(context.Background(), constants.UserIDKey, int64(1))
+	otherCtx := context.WithValue(context.Background(), constants.UserIDKey, int64(2))
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	db, err := NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	links := NewLinksRepository(db)
+	repo := NewClicksRepository(db)
+
+	_, err = links.Add(ownerCtx, models.AddedLink{Short: "short", Origin: "https://test.com/"})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.AddBatch(ownerCtx, []models.Click{
+		{Short: "short", ClickedAt: day.Add(time.Hour), Referrer: "https://ref.example/", UserAgent: "test", IPHash: "hash"},
+		{Short: "short", ClickedAt: day.Add(2 * time.Hour)},
+	}))
+	require.NoError(t, repo.AddBatch(ownerCtx, []models.Click{{Short: "short", ClickedAt: day.Add(25 * time.Hour)}}))
+	require.NoError(t, repo.AddBatch(ownerCtx, nil))
+
+	want := models.LinkStats{
+		Short: "short",
+		Total: 3,
+		Daily: []models.DailyClicks{{Day: day, Clicks: 2}, {Day: day.Add(24 * time.Hour), Clicks: 1}},
+	}
+	stats, err := repo.GetStats(ownerCtx, "short")
+	require.NoError(t, err)
+	assert.Equal(t, want, stats)
+
+	_, err = repo.GetStats(otherCtx, "short")
+	assert.ErrorIs(t, err, services.ErrLinkNotFound, "only the owner sees the stats")
+	_, err = repo.GetStats(ownerCtx, "missing")
+	assert.ErrorIs(t, err, services.ErrLinkNotFound)
+	require.NoError(t, db.Close())
+
+	db, err = NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewClicksRepository(db)
+
+	stats, err = repo.GetStats(ownerCtx, "short")
+	require.NoError(t, err)
+	assert.Equal(t, want, stats, "clicks survive a restart")
+	assert.Equal(t, "hash", db.clicks["short"][0].IPHash)
+}
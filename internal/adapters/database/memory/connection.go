@@ -3,13 +3,45 @@ package memory
 import (
 	"go.uber.org/zap"
 	"main/internal/interfaces"
+	"main/internal/models"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// link holds a stored short link along with its metadata.
+type link struct {
+	origin    string    // Original long URL.
+	expiresAt time.Time // Moment after which the link expires, zero if it never does.
+	createdAt time.Time // Moment the link was created, zero for links persisted before creation times were recorded.
+	userID    int64     // Identifier of the owner, zero for links created without a user.
+	deleted   bool      // Indicates whether the link was soft-deleted by its owner.
+	deletedAt time.Time // Moment the link was soft-deleted, zero if it is not deleted.
+}
+
+// expired reports whether the link has expired by the given moment.
+func (l *link) expired(now time.Time) bool {
+	return !l.expiresAt.IsZero() && !l.expiresAt.After(now)
+}
+
 // InMemoryDB represents an in-memory database backed by file storage.
+// It is safe for concurrent use.
 type InMemoryDB struct {
-	links      map[string]string              // Map holding the short-to-long URL mappings.
-	producerFS interfaces.FileStorageProducer // Interface implementation for writing to persistent storage.
-	consumerFS interfaces.FileStorageConsumer // Interface implementation for reading from persistent storage.
+	links       *shardedLinks                  // Sharded map holding the short-to-long URL mappings.
+	lastEventID atomic.Int64                   // Identifier of the most recently written event.
+	lastUserID  atomic.Int64                   // Identifier of the most recently registered user.
+	userLinks   map[int64][]string             // Short links owned by each user in creation order.
+	apiKeys     map[string]models.APIKey       // API keys by the hash of the key.
+	sessions    map[string]models.RefreshToken // Refresh tokens by the hash of the token.
+	accounts    map[int64]models.User          // Registered accounts by user ID.
+	usernames   map[string]int64               // User IDs of registered accounts by username.
+	usersMu     sync.RWMutex                   // Guards the userLinks, apiKeys, sessions, accounts and usernames maps.
+	clicks      map[string][]models.Click      // Clicks registered for each short link.
+	clicksMu    sync.RWMutex                   // Guards the clicks map written by the background recorder.
+	rateLimits  map[string]rateLimitBucket     // Token buckets of rate limits by key; not persisted.
+	rateMu      sync.Mutex                     // Guards the rateLimits map.
+	producerFS  interfaces.FileStorageProducer // Interface implementation for writing to persistent storage.
+	consumerFS  interfaces.FileStorageConsumer // Interface implementation for reading from persistent storage.
 }
 
 // Close closes both the producer and consumer file storages.
@@ -42,8 +74,15 @@ func NewInMemoryDB(path string, logger *zap.SugaredLogger) (*InMemoryDB, error)
 		logger.Infow("Failed to create consumer file storage", "error", err.Error())
 		return nil, err
 	}
-	db := InMemoryDB{
-		links:      make(map[string]string),
+	db := &InMemoryDB{
+		links:      newShardedLinks(),
+		userLinks:  make(map[int64][]string),
+		apiKeys:    make(map[string]models.APIKey),
+		sessions:   make(map[string]models.RefreshToken),
+		accounts:   make(map[int64]models.User),
+		usernames:  make(map[string]int64),
+		clicks:     make(map[string][]models.Click),
+		rateLimits: make(map[string]rateLimitBucket),
 		producerFS: producerFS,
 		consumerFS: consumerFS,
 	}
@@ -52,17 +91,174 @@ func NewInMemoryDB(path string, logger *zap.SugaredLogger) (*InMemoryDB, error)
 		logger.Infow("Failed to load events from file", "error", err.Error())
 		return nil, err
 	}
-	return &db, nil
+	return db, nil
 }
 
-// loadFromFile loads existing URL mapping events from the consumer file storage into memory.
+// loadFromFile replays events from the consumer file storage to restore links, updates, users, accounts, API keys, sessions, deletions, purges and clicks in memory.
 func (db *InMemoryDB) loadFromFile() error {
 	events, err := db.consumerFS.ReadAllEvents()
 	if err != nil {
 		return err
 	}
 	for _, event := range events {
-		db.links[event.Short] = event.Origin
+		switch event.Type {
+		case models.UserAddedEvent:
+			if event.UserID > db.lastUserID.Load() {
+				db.lastUserID.Store(event.UserID)
+			}
+		case models.APIKeyAddedEvent:
+			if event.APIKey != nil {
+				db.apiKeys[event.APIKey.Hash] = *event.APIKey
+			}
+		case models.APIKeyRevokedEvent:
+			if event.APIKey != nil {
+				delete(db.apiKeys, event.APIKey.Hash)
+			}
+		case models.SessionSavedEvent:
+			if event.Session != nil {
+				db.sessions[event.Session.Hash] = *event.Session
+			}
+		case models.SessionDeletedEvent:
+			if event.Session != nil {
+				delete(db.sessions, event.Session.Hash)
+			}
+		case models.AccountAddedEvent:
+			if event.Account != nil {
+				db.accounts[event.Account.ID] = *event.Account
+				db.usernames[event.Account.Username] = event.Account.ID
+			}
+		case models.LinksClaimedEvent:
+			claimed := db.claimableLinks(event.FromUser, db.userLinks[event.FromUser])
+			db.moveUserLinks(event.FromUser, event.UserID, claimed)
+		case models.LinkUpdatedEvent:
+			if l, ok := db.links.shardFor(event.Short).links[event.Short]; ok {
+				db.links.setOrigin(event.Short, l, event.Origin)
+			}
+		case models.LinkDeletedEvent:
+			if l, ok := db.links.shardFor(event.Short).links[event.Short]; ok {
+				l.deleted = true
+				// Deletions stored before the deletion time was recorded count from the moment they are loaded.
+				l.deletedAt = time.Now()
+				if event.DeletedAt != nil {
+					l.deletedAt = *event.DeletedAt
+				}
+			}
+		case models.LinkRestoredEvent:
+			if l, ok := db.links.shardFor(event.Short).links[event.Short]; ok {
+				l.deleted, l.deletedAt = false, time.Time{}
+			}
+		case models.ClicksAddedEvent:
+			clicks := make([]models.Click, len(event.Clicks))
+			for i, click := range event.Clicks {
+				clicks[i] = models.Click(click)
+			}
+			db.addClicks(clicks)
+		case models.LinkPurgedEvent:
+			if l, ok := db.links.shardFor(event.Short).links[event.Short]; ok {
+				db.links.remove(event.Short, l)
+				db.removeUserLink(l.userID, event.Short)
+			}
+		default:
+			l := &link{
+				origin: event.Origin,
+				userID: event.UserID,
+			}
+			if event.ExpiresAt != nil {
+				l.expiresAt = *event.ExpiresAt
+			}
+			if event.CreatedAt != nil {
+				l.createdAt = *event.CreatedAt
+			}
+			db.links.shardFor(event.Short).links[event.Short] = l
+			db.addUserLink(event.UserID, event.Short)
+
+			origins := db.links.shardFor(event.Origin).origins
+			if _, ok := origins[event.Origin]; !ok {
+				origins[event.Origin] = event.Short
+			}
+		}
+
+		if int64(event.ID) > db.lastEventID.Load() {
+			db.lastEventID.Store(int64(event.ID))
+		}
 	}
 	return nil
 }
+
+// addUserLink records a short link in the list of links owned by a user.
+// Links created without a user are not indexed.
+func (db *InMemoryDB) addUserLink(userID int64, short string) {
+	if userID == 0 {
+		return
+	}
+	db.usersMu.Lock()
+	defer db.usersMu.Unlock()
+
+	db.userLinks[userID] = append(db.userLinks[userID], short)
+}
+
+// removeUserLink drops a short link from the list of links owned by a user.
+func (db *InMemoryDB) removeUserLink(userID int64, short string) {
+	db.usersMu.Lock()
+	defer db.usersMu.Unlock()
+
+	shorts := db.userLinks[userID]
+	for i, s := range shorts {
+		if s == short {
+			db.userLinks[userID] = append(shorts[:i:i], shorts[i+1:]...)
+			break
+		}
+	}
+	if len(db.userLinks[userID]) == 0 {
+		delete(db.userLinks, userID)
+	}
+}
+
+// addClicks registers clicks of short links; the caller must hold clicksMu or be loading the storage.
+func (db *InMemoryDB) addClicks(clicks []models.Click) {
+	for _, click := range clicks {
+		db.clicks[click.Short] = append(db.clicks[click.Short], click)
+	}
+}
+
+// claimableLinks returns the given short links that still exist and are owned by the user.
+// The list of links of a user may name links removed since they were added, so the links are looked up.
+// The caller must hold the locks of the links or be loading the storage.
+func (db *InMemoryDB) claimableLinks(userID int64, shorts []string) []string {
+	var claimable []string
+	for _, short := range shorts {
+		if l, ok := db.links.shardFor(short).links[short]; ok && l.userID == userID {
+			claimable = append(claimable, short)
+		}
+	}
+	return claimable
+}
+
+// moveUserLinks transfers the claimed links from one user to another. The other entries of the first user's list
+// are kept wherever they are in it, since the claimed links are not necessarily a prefix of the list.
+// The caller must hold usersMu and the locks of the links or be loading the storage.
+func (db *InMemoryDB) moveUserLinks(fromUserID, toUserID int64, claimed []string) {
+	isClaimed := make(map[string]bool, len(claimed))
+	for _, short := range claimed {
+		isClaimed[short] = true
+		db.links.shardFor(short).links[short].userID = toUserID
+	}
+	db.userLinks[toUserID] = append(db.userLinks[toUserID], claimed...)
+
+	var rest []string
+	for _, short := range db.userLinks[fromUserID] {
+		if !isClaimed[short] {
+			rest = append(rest, short)
+		}
+	}
+	if len(rest) > 0 {
+		db.userLinks[fromUserID] = rest
+	} else {
+		delete(db.userLinks, fromUserID)
+	}
+}
+
+// nextEventID returns a new monotonically increasing event identifier.
+func (db *InMemoryDB) nextEventID() int {
+	return int(db.lastEventID.Add(1))
+}
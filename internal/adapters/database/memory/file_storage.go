@@ -8,10 +8,13 @@ import (
 	"main/internal/models"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 // FileProducer writes events to a file using buffered I/O.
+// It is safe for concurrent use.
 type FileProducer struct {
+	mu     sync.Mutex    // Serializes writes to the buffered writer.
 	file   *os.File      // Underlying file handle.
 	writer *bufio.Writer // Buffered writer for efficient writes.
 }
@@ -70,6 +73,9 @@ func (fs *FileProducer) WriteEvent(event *models.Event) error {
 		return err
 	}
 
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
 	_, err = fs.writer.Write(data)
 	if err != nil {
 		return err
@@ -85,6 +91,9 @@ func (fs *FileProducer) WriteEvent(event *models.Event) error {
 
 // Close flushes any remaining data and closes the file handle for the producer.
 func (fs *FileProducer) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
 	if err := fs.writer.Flush(); err != nil {
 		return err
 	}
@@ -3,7 +3,10 @@ package memory
 import (
 	"context"
 	"fmt"
+	"main/internal/constants"
 	"main/internal/models"
+	"main/internal/services"
+	"time"
 )
 
 // LinksRepository manages operations related to adding and retrieving links from the in-memory database.
@@ -19,18 +22,27 @@ func NewLinksRepository(db *InMemoryDB) *LinksRepository {
 }
 
 // Add inserts a new link into the database and persists the change to file storage.
+// If the origin has already been shortened, it returns the existing short link along with services.ErrConflict.
+// It returns services.ErrShortLinkTaken if the short link is already in use.
 func (r *LinksRepository) Add(ctx context.Context, addedLink models.AddedLink) (string, error) {
 	select {
 	case <-ctx.Done():
 		return "", ctx.Err()
 	default:
-		r.db.links[addedLink.Short] = addedLink.Origin
+		unlock := r.db.links.lock([]string{addedLink.Short, addedLink.Origin})
+		defer unlock()
 
-		event := &models.Event{
-			ID:     len(r.db.links),
-			Origin: addedLink.Origin,
-			Short:  addedLink.Short,
+		if short, ok := r.db.links.shardFor(addedLink.Origin).origins[addedLink.Origin]; ok {
+			return short, services.ErrConflict
+		}
+		if _, ok := r.db.links.shardFor(addedLink.Short).links[addedLink.Short]; ok {
+			return "", services.ErrShortLinkTaken
 		}
+
+		userID, createdAt := userIDFromContext(ctx), time.Now().UTC()
+		r.insert(addedLink, userID, createdAt)
+
+		event := newLinkEvent(r.db.nextEventID(), addedLink, userID, createdAt)
 		if err := r.db.producerFS.WriteEvent(event); err != nil {
 			return "", err
 		}
@@ -40,43 +52,271 @@ func (r *LinksRepository) Add(ctx context.Context, addedLink models.AddedLink) (
 }
 
 // AddBatch adds multiple links in batch fashion, persisting changes to file storage.
+// Items whose origin has already been shortened, including earlier in the same batch, are reported
+// with the existing short link instead of being added.
+// Nothing is added if any of the new short links is already in use (services.ErrShortLinkTaken).
 func (r *LinksRepository) AddBatch(ctx context.Context, addedLinks []models.AddedLink) ([]models.Result, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		var results []models.Result
-
+		keys := make([]string, 0, 2*len(addedLinks))
 		for _, addedLink := range addedLinks {
-			r.db.links[addedLink.Short] = addedLink.Origin
+			keys = append(keys, addedLink.Short, addedLink.Origin)
+		}
+		unlock := r.db.links.lock(keys)
+		defer unlock()
 
-			event := &models.Event{
-				ID:     len(r.db.links),
-				Origin: addedLink.Origin,
-				Short:  addedLink.Short,
+		results := make([]models.Result, len(addedLinks))
+		origins := make(map[string]string, len(addedLinks))
+		for i, addedLink := range addedLinks {
+			results[i].CorrelationID = addedLink.CorrelationID
+			short, ok := r.db.links.shardFor(addedLink.Origin).origins[addedLink.Origin]
+			if !ok {
+				short, ok = origins[addedLink.Origin]
 			}
+			if ok {
+				results[i].Result, results[i].Status = short, constants.LinkExists
+				continue
+			}
+			if _, ok := r.db.links.shardFor(addedLink.Short).links[addedLink.Short]; ok {
+				return nil, services.ErrShortLinkTaken
+			}
+			origins[addedLink.Origin] = addedLink.Short
+			results[i].Result, results[i].Status = addedLink.Short, constants.LinkCreated
+		}
+
+		userID, createdAt := userIDFromContext(ctx), time.Now().UTC()
+
+		for i, addedLink := range addedLinks {
+			if results[i].Status != constants.LinkCreated {
+				continue
+			}
+			r.insert(addedLink, userID, createdAt)
+
+			event := newLinkEvent(r.db.nextEventID(), addedLink, userID, createdAt)
 			if err := r.db.producerFS.WriteEvent(event); err != nil {
 				return nil, err
 			}
-			result := models.Result{
-				CorrelationID: addedLink.CorrelationID,
-				Result:        addedLink.Short,
+		}
+		return results, nil
+	}
+}
+
+// Import adds links with preset short codes, persisting changes to file storage.
+// Unlike AddBatch, a short code already in use, including earlier in the same batch, does not fail the batch:
+// such items are reported as taken, while items whose origin has already been shortened are reported as existing.
+func (r *LinksRepository) Import(ctx context.Context, addedLinks []models.AddedLink) ([]models.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		keys := make([]string, 0, 2*len(addedLinks))
+		for _, addedLink := range addedLinks {
+			keys = append(keys, addedLink.Short, addedLink.Origin)
+		}
+		unlock := r.db.links.lock(keys)
+		defer unlock()
+
+		userID, createdAt := userIDFromContext(ctx), time.Now().UTC()
+
+		results := make([]models.Result, len(addedLinks))
+		for i, addedLink := range addedLinks {
+			results[i].CorrelationID = addedLink.CorrelationID
+			if short, ok := r.db.links.shardFor(addedLink.Origin).origins[addedLink.Origin]; ok {
+				results[i].Result, results[i].Status = short, constants.LinkExists
+				continue
+			}
+			if _, ok := r.db.links.shardFor(addedLink.Short).links[addedLink.Short]; ok {
+				results[i].Result, results[i].Status = addedLink.Short, constants.LinkTaken
+				continue
 			}
-			results = append(results, result)
+			r.insert(addedLink, userID, createdAt)
+
+			event := newLinkEvent(r.db.nextEventID(), addedLink, userID, createdAt)
+			if err := r.db.producerFS.WriteEvent(event); err != nil {
+				return nil, err
+			}
+			results[i].Result, results[i].Status = addedLink.Short, constants.LinkCreated
 		}
 		return results, nil
 	}
 }
 
+// insert stores a link along with its origin index and ownership entries.
+// The caller must hold the locks of the shards responsible for the short link and its origin.
+func (r *LinksRepository) insert(addedLink models.AddedLink, userID int64, createdAt time.Time) {
+	r.db.links.shardFor(addedLink.Short).links[addedLink.Short] = newLink(addedLink, userID, createdAt)
+	r.db.links.shardFor(addedLink.Origin).origins[addedLink.Origin] = addedLink.Short
+	r.db.addUserLink(userID, addedLink.Short)
+}
+
 // Get retrieves the original URL corresponding to a given shortened link.
+// Unknown, expired and deleted links are reported with services.ErrLinkNotFound, ErrExpiredLink and ErrDeletedLink.
 func (r *LinksRepository) Get(ctx context.Context, short string) (string, error) {
 	select {
 	case <-ctx.Done():
 		return "", ctx.Err()
 	default:
-		if link, ok := r.db.links[short]; ok {
-			return link, nil
+		l, ok := r.db.links.get(short)
+		if !ok {
+			return "", fmt.Errorf("%w: short code '%s'", services.ErrLinkNotFound, short)
+		}
+		if l.expired(time.Now()) {
+			return "", services.ErrExpiredLink
+		}
+		if l.deleted {
+			return "", services.ErrDeletedLink
+		}
+		return l.origin, nil
+	}
+}
+
+// DeleteExpired removes links that expired before the given moment, persists the removals
+// and returns how many were removed. The origins of removed links become available for shortening again.
+func (r *LinksRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		return r.purge(func(l *link) bool {
+			return l.expired(before)
+		})
+	}
+}
+
+// DeleteSoftDeleted permanently removes links soft-deleted before the given moment, persists the removals
+// and returns how many were removed. The origins of removed links become available for shortening again.
+func (r *LinksRepository) DeleteSoftDeleted(ctx context.Context, before time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		return r.purge(func(l *link) bool {
+			return l.deleted && l.deletedAt.Before(before)
+		})
+	}
+}
+
+// purge permanently removes the links matching the predicate, writing a purge event for each,
+// and returns how many were removed.
+func (r *LinksRepository) purge(purgeable func(l *link) bool) (int64, error) {
+	var purged int64
+	for short, origin := range r.db.links.collect(purgeable) {
+		unlock := r.db.links.lock([]string{short, origin})
+		l, ok := r.db.links.shardFor(short).links[short]
+		if !ok || l.origin != origin || !purgeable(l) {
+			unlock()
+			continue
+		}
+		event := &models.Event{
+			ID:     r.db.nextEventID(),
+			Type:   models.LinkPurgedEvent,
+			Short:  short,
+			Origin: origin,
+			UserID: l.userID,
+		}
+		if err := r.db.producerFS.WriteEvent(event); err != nil {
+			unlock()
+			return purged, err
+		}
+		r.db.links.remove(short, l)
+		unlock()
+
+		r.db.removeUserLink(l.userID, short)
+		purged++
+	}
+	return purged, nil
+}
+
+// Update changes the destination of a link owned by the current user and persists the change,
+// keeping the previous destination in the event history.
+// It returns services.ErrLinkNotFound if the user has no such link or it is deleted
+// and services.ErrConflict if the new origin has already been shortened.
+func (r *LinksRepository) Update(ctx context.Context, short, origin string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		userID := userIDFromContext(ctx)
+
+		for {
+			current, ok := r.db.links.get(short)
+			if !ok || current.userID != userID || current.deleted {
+				return services.ErrLinkNotFound
+			}
+			if current.origin == origin {
+				return nil
+			}
+
+			// The shard of the previous origin has to be locked too, so the link is re-read under the locks
+			// and the update is retried if its origin changed in the meantime.
+			unlock := r.db.links.lock([]string{short, origin, current.origin})
+			l, ok := r.db.links.shardFor(short).links[short]
+			if !ok || l.origin != current.origin {
+				unlock()
+				continue
+			}
+			err := r.update(l, short, origin, userID)
+			unlock()
+			return err
 		}
-		return "", fmt.Errorf("link with short code '%s' not found", short)
 	}
 }
+
+// update moves a locked link to a new origin and persists the change.
+func (r *LinksRepository) update(l *link, short, origin string, userID int64) error {
+	if l.userID != userID || l.deleted {
+		return services.ErrLinkNotFound
+	}
+	if _, ok := r.db.links.shardFor(origin).origins[origin]; ok {
+		return services.ErrConflict
+	}
+
+	event := &models.Event{
+		ID:             r.db.nextEventID(),
+		Type:           models.LinkUpdatedEvent,
+		Short:          short,
+		Origin:         origin,
+		PreviousOrigin: l.origin,
+		UserID:         userID,
+	}
+	if err := r.db.producerFS.WriteEvent(event); err != nil {
+		return err
+	}
+	r.db.links.setOrigin(short, l, origin)
+	return nil
+}
+
+// newLink converts an added link into its in-memory representation.
+func newLink(addedLink models.AddedLink, userID int64, createdAt time.Time) *link {
+	return &link{
+		origin:    addedLink.Origin,
+		expiresAt: addedLink.ExpiresAt,
+		createdAt: createdAt,
+		userID:    userID,
+	}
+}
+
+// newLinkEvent builds a file storage event describing an added link.
+func newLinkEvent(id int, addedLink models.AddedLink, userID int64, createdAt time.Time) *models.Event {
+	event := &models.Event{
+		ID:        id,
+		Type:      models.LinkAddedEvent,
+		Origin:    addedLink.Origin,
+		Short:     addedLink.Short,
+		UserID:    userID,
+		CreatedAt: &createdAt,
+	}
+	if !addedLink.ExpiresAt.IsZero() {
+		expiresAt := addedLink.ExpiresAt
+		event.ExpiresAt = &expiresAt
+	}
+	return event
+}
+
+// userIDFromContext extracts the current user ID from the request context, returning zero if there is none.
+func userIDFromContext(ctx context.Context) int64 {
+	userID, _ := ctx.Value(constants.UserIDKey).(int64)
+	return userID
+}
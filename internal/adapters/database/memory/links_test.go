@@ -2,10 +2,20 @@ package memory
 
 import (
 	"context"
+	"fmt"
 	"main/internal/adapters"
+	"main/internal/constants"
 	"main/internal/models"
+	"main/internal/services"
 	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func BenchmarkInMemoryMethods(b *testing.B) {
@@ -35,10 +45,15 @@ func BenchmarkInMemoryMethods(b *testing.B) {
 		addedLinks = append(addedLinks, link)
 	}
 
+	seq := 0
+
 	b.ResetTimer()
 
 	b.Run("Add", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
+			seq++
+			addedLink.Short = short + strconv.Itoa(seq)
+			addedLink.Origin = "Origin" + strconv.Itoa(seq)
 			_, err := repo.Add(ctx, addedLink)
 			if err != nil {
 				logger.Fatalw(err.Error(), "event", "Add")
@@ -47,6 +62,11 @@ func BenchmarkInMemoryMethods(b *testing.B) {
 	})
 	b.Run("AddBatch", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
+			seq++
+			for j := range addedLinks {
+				addedLinks[j].Short = short + strconv.Itoa(seq) + "-" + strconv.Itoa(j)
+				addedLinks[j].Origin = "Origin" + strconv.Itoa(seq) + "-" + strconv.Itoa(j)
+			}
 			_, err := repo.AddBatch(ctx, addedLinks)
 			if err != nil {
 				logger.Fatalw(err.Error(), "event", "AddBatch")
@@ -54,3 +74,211 @@ func BenchmarkInMemoryMethods(b *testing.B) {
 		}
 	})
 }
+
+func TestLinksRepositoryConcurrency(t *testing.T) {
+	const workers = 16
+	const perWorker = 200
+
+	logger := adapters.GetLogger()
+	ctx := context.Background()
+
+	db, err := NewInMemoryDB(filepath.Join(t.TempDir(), "race.jsonl"), logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewLinksRepository(db)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(3)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				short := fmt.Sprintf("add-%d-%d", w, i)
+				_, err := repo.Add(ctx, models.AddedLink{Short: short, Origin: "https://example.com/" + short})
+				assert.NoError(t, err)
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i += 10 {
+				var batch []models.AddedLink
+				for j := i; j < i+10; j++ {
+					short := fmt.Sprintf("batch-%d-%d", w, j)
+					batch = append(batch, models.AddedLink{Short: short, Origin: "https://example.com/" + short})
+				}
+				_, err := repo.AddBatch(ctx, batch)
+				assert.NoError(t, err)
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				_, _ = repo.Get(ctx, fmt.Sprintf("add-%d-%d", (w+1)%workers, i))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for w := 0; w < workers; w++ {
+		for i := 0; i < perWorker; i++ {
+			origin, err := repo.Get(ctx, fmt.Sprintf("batch-%d-%d", w, i))
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("https://example.com/batch-%d-%d", w, i), origin)
+		}
+	}
+	assert.Equal(t, int64(2*workers*perWorker), db.lastEventID.Load())
+
+	_, err = repo.Add(ctx, models.AddedLink{Short: "add-0-0", Origin: "https://example.com/dup"})
+	assert.ErrorIs(t, err, services.ErrShortLinkTaken)
+}
+
+func TestLinksRepositoryOriginConflict(t *testing.T) {
+	logger := adapters.GetLogger()
+	ctx := context.Background()
+
+	db, err := NewInMemoryDB(filepath.Join(t.TempDir(), "conflict.jsonl"), logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewLinksRepository(db)
+
+	_, err = repo.Add(ctx, models.AddedLink{Short: "first", Origin: "https://example.com"})
+	require.NoError(t, err)
+
+	short, err := repo.Add(ctx, models.AddedLink{Short: "second", Origin: "https://example.com"})
+	assert.ErrorIs(t, err, services.ErrConflict)
+	assert.Equal(t, "first", short)
+
+	results, err := repo.AddBatch(ctx, []models.AddedLink{
+		{CorrelationID: "1", Short: "third", Origin: "https://example.org"},
+		{CorrelationID: "2", Short: "fourth", Origin: "https://example.com"},
+		{CorrelationID: "3", Short: "fifth", Origin: "https://example.org"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Result{
+		{CorrelationID: "1", Result: "third", Status: constants.LinkCreated},
+		{CorrelationID: "2", Result: "first", Status: constants.LinkExists},
+		{CorrelationID: "3", Result: "third", Status: constants.LinkExists},
+	}, results)
+
+	_, err = repo.Get(ctx, "fourth")
+	assert.Error(t, err)
+	_, err = repo.Get(ctx, "fifth")
+	assert.Error(t, err)
+}
+
+func TestLinksRepositoryUpdate(t *testing.T) {
+	logger := adapters.GetLogger()
+	path := filepath.Join(t.TempDir(), "update.jsonl")
+	ctx := context.WithValue(context.Background(), constants.UserIDKey, int64(1))
+	otherCtx := context.WithValue(context.Background(), constants.UserIDKey, int64(2))
+
+	db, err := NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	repo := NewLinksRepository(db)
+
+	_, err = repo.Add(ctx, models.AddedLink{Short: "flyer", Origin: "https://old.example"})
+	require.NoError(t, err)
+	_, err = repo.Add(ctx, models.AddedLink{Short: "other", Origin: "https://other.example"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, repo.Update(otherCtx, "flyer", "https://new.example"), services.ErrLinkNotFound)
+	assert.ErrorIs(t, repo.Update(ctx, "missing", "https://new.example"), services.ErrLinkNotFound)
+	assert.ErrorIs(t, repo.Update(ctx, "flyer", "https://other.example"), services.ErrConflict)
+	require.NoError(t, repo.Update(ctx, "flyer", "https://new.example"))
+	require.NoError(t, db.Close())
+
+	db, err = NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewLinksRepository(db)
+
+	origin, err := repo.Get(ctx, "flyer")
+	require.NoError(t, err)
+	assert.Equal(t, "https://new.example", origin)
+
+	short, err := repo.Add(ctx, models.AddedLink{Short: "again", Origin: "https://new.example"})
+	assert.ErrorIs(t, err, services.ErrConflict)
+	assert.Equal(t, "flyer", short)
+	_, err = repo.Add(ctx, models.AddedLink{Short: "again", Origin: "https://old.example"})
+	assert.NoError(t, err)
+}
+
+func TestLinksRepositoryImport(t *testing.T) {
+	logger := adapters.GetLogger()
+	path := filepath.Join(t.TempDir(), "import.jsonl")
+	ctx := context.WithValue(context.Background(), constants.UserIDKey, int64(1))
+
+	db, err := NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	repo := NewLinksRepository(db)
+
+	_, err = repo.Add(ctx, models.AddedLink{Short: "old", Origin: "https://old.example"})
+	require.NoError(t, err)
+
+	results, err := repo.Import(ctx, []models.AddedLink{
+		{CorrelationID: "1", Short: "new", Origin: "https://new.example"},
+		{CorrelationID: "2", Short: "old", Origin: "https://taken.example"},
+		{CorrelationID: "3", Short: "new", Origin: "https://duplicate.example"},
+		{CorrelationID: "4", Short: "fresh", Origin: "https://old.example"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Result{
+		{CorrelationID: "1", Result: "new", Status: constants.LinkCreated},
+		{CorrelationID: "2", Result: "old", Status: constants.LinkTaken},
+		{CorrelationID: "3", Result: "new", Status: constants.LinkTaken},
+		{CorrelationID: "4", Result: "old", Status: constants.LinkExists},
+	}, results)
+	require.NoError(t, db.Close())
+
+	db, err = NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewLinksRepository(db)
+
+	origin, err := repo.Get(ctx, "new")
+	require.NoError(t, err)
+	assert.Equal(t, "https://new.example", origin)
+	_, err = repo.Get(ctx, "fresh")
+	assert.Error(t, err)
+}
+
+func TestLinksRepositoryDeleteExpired(t *testing.T) {
+	logger := adapters.GetLogger()
+	path := filepath.Join(t.TempDir(), "expired.jsonl")
+	ctx := context.WithValue(context.Background(), constants.UserIDKey, int64(1))
+	now := time.Now()
+
+	db, err := NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	repo := NewLinksRepository(db)
+	users := NewUsersRepository(db)
+
+	_, err = repo.Add(ctx, models.AddedLink{Short: "gone", Origin: "https://reused.example", ExpiresAt: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	_, err = repo.Add(ctx, models.AddedLink{Short: "kept", Origin: "https://kept.example", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	deleted, err := repo.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	links, err := users.GetLinks(ctx, models.LinksQuery{})
+	require.NoError(t, err)
+	require.Len(t, links, 1, "removed links no longer belong to their user")
+	assert.Equal(t, "https://kept.example", links[0].Original)
+
+	_, err = repo.Add(ctx, models.AddedLink{Short: "again", Origin: "https://reused.example"})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db, err = NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewLinksRepository(db)
+
+	_, err = repo.Get(ctx, "gone")
+	assert.ErrorIs(t, err, services.ErrLinkNotFound, "removals survive a restart")
+	short, err := repo.Add(ctx, models.AddedLink{Short: "third", Origin: "https://reused.example"})
+	assert.ErrorIs(t, err, services.ErrConflict)
+	assert.Equal(t, "again", short, "the origin belongs to the link created after the removal")
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"main/internal/models"
+	"time"
+)
+
+// rateLimitBucket is a token bucket of a rate limit.
+type rateLimitBucket struct {
+	tokens    float64   // Tokens left after the last request.
+	updatedAt time.Time // Moment of the last request.
+}
+
+// RateLimitRepository manages the token buckets of rate limits kept in the in-memory database.
+// The buckets live in the process only, so every instance of the service enforces its own limits.
+type RateLimitRepository struct {
+	db *InMemoryDB // Pointer to the in-memory database instance.
+}
+
+// NewRateLimitRepository creates a new instance of RateLimitRepository bound to a specific InMemoryDB.
+func NewRateLimitRepository(db *InMemoryDB) *RateLimitRepository {
+	return &RateLimitRepository{
+		db: db,
+	}
+}
+
+// Take refills the bucket of the key for the time passed since its last request and takes a token from it
+// if there is one. Unknown keys start with a full bucket.
+func (r *RateLimitRepository) Take(ctx context.Context, key string, limit models.RateLimit, now time.Time) (models.RateLimitBucket, error) {
+	select {
+	case <-ctx.Done():
+		return models.RateLimitBucket{}, ctx.Err()
+	default:
+		r.db.rateMu.Lock()
+		defer r.db.rateMu.Unlock()
+
+		bucket, ok := r.db.rateLimits[key]
+		if !ok {
+			bucket = rateLimitBucket{tokens: float64(limit.Requests), updatedAt: now}
+		}
+		if elapsed := now.Sub(bucket.updatedAt); elapsed > 0 {
+			bucket.tokens = min(float64(limit.Requests), bucket.tokens+elapsed.Seconds()*limit.Rate())
+			bucket.updatedAt = now
+		}
+
+		allowed := bucket.tokens >= 1
+		if allowed {
+			bucket.tokens--
+		}
+		r.db.rateLimits[key] = bucket
+		return models.RateLimitBucket{Tokens: bucket.tokens, Allowed: allowed}, nil
+	}
+}
+
+// DeleteIdle removes the buckets last used before the given moment and returns how many were removed.
+func (r *RateLimitRepository) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		r.db.rateMu.Lock()
+		defer r.db.rateMu.Unlock()
+
+		var deleted int64
+		for key, bucket := range r.db.rateLimits {
+			if bucket.updatedAt.Before(before) {
+				delete(r.db.rateLimits, key)
+				deleted++
+			}
+		}
+		return deleted, nil
+	}
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"main/internal/adapters"
+	"main/internal/models"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitRepositoryTake(t *testing.T) {
+	db, err := NewInMemoryDB(filepath.Join(t.TempDir(), "limits.jsonl"), adapters.GetLogger())
+	require.NoError(t, err)
+	repo := NewRateLimitRepository(db)
+	ctx := context.Background()
+
+	limit := models.RateLimit{Requests: 2, Period: 2 * time.Second}
+	now := time.Now()
+
+	take := func(key string, at time.Time) models.RateLimitBucket {
+		bucket, err := repo.Take(ctx, key, limit, at)
+		require.NoError(t, err)
+		return bucket
+	}
+
+	assert.Equal(t, models.RateLimitBucket{Tokens: 1, Allowed: true}, take("a", now))
+	assert.Equal(t, models.RateLimitBucket{Tokens: 0, Allowed: true}, take("a", now))
+	assert.Equal(t, models.RateLimitBucket{Tokens: 0, Allowed: false}, take("a", now))
+	assert.True(t, take("b", now).Allowed, "keys must have separate buckets")
+
+	bucket := take("a", now.Add(500*time.Millisecond))
+	assert.False(t, bucket.Allowed, "half a token is not enough")
+	assert.InDelta(t, 0.5, bucket.Tokens, 1e-9)
+
+	assert.True(t, take("a", now.Add(time.Second)).Allowed, "a token is refilled every second")
+	assert.InDelta(t, 1, take("a", now.Add(time.Hour)).Tokens, 1e-9, "refills are capped by the bucket size")
+
+	deleted, err := repo.DeleteIdle(ctx, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, models.RateLimitBucket{Tokens: 1, Allowed: true}, take("b", now.Add(time.Minute)), "removed buckets start full")
+}
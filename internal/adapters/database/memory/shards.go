@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// shardsCount defines how many independently locked shards hold the links.
+const shardsCount = 32
+
+// linksShard holds a portion of the links and of the reverse origin index guarded by its own lock.
+type linksShard struct {
+	mu      sync.RWMutex      // Guards the maps of the shard.
+	links   map[string]*link  // Short-to-link mappings whose short hashes into this shard.
+	origins map[string]string // Origin-to-short mappings whose origin hashes into this shard.
+}
+
+// shardedLinks spreads links across independently locked shards to reduce lock contention.
+// Short links and origins are both used as shard keys, so an operation touching a link and its origin
+// must lock the shards of both keys.
+type shardedLinks struct {
+	shards [shardsCount]linksShard // Fixed set of shards addressed by the hash of a short link.
+}
+
+// newShardedLinks creates an empty sharded links map.
+func newShardedLinks() *shardedLinks {
+	s := &shardedLinks{}
+	for i := range s.shards {
+		s.shards[i].links = make(map[string]*link)
+		s.shards[i].origins = make(map[string]string)
+	}
+	return s
+}
+
+// shardIndex returns the index of the shard responsible for a key.
+func shardIndex(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % shardsCount)
+}
+
+// shardFor returns the shard responsible for a key.
+func (s *shardedLinks) shardFor(key string) *linksShard {
+	return &s.shards[shardIndex(key)]
+}
+
+// get returns a copy of the link stored under the short code.
+func (s *shardedLinks) get(short string) (link, bool) {
+	shard := s.shardFor(short)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+
+	l, ok := shard.links[short]
+	if !ok {
+		return link{}, false
+	}
+	return *l, true
+}
+
+// collect returns the short links matching a condition along with their origins.
+// The shards are scanned one by one, so callers must re-check the links under the write locks.
+func (s *shardedLinks) collect(match func(l *link) bool) map[string]string {
+	matched := make(map[string]string)
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.RLock()
+		for short, l := range shard.links {
+			if match(l) {
+				matched[short] = l.origin
+			}
+		}
+		shard.mu.RUnlock()
+	}
+	return matched
+}
+
+// remove deletes a link along with its origin index entry.
+// The caller must hold the locks of the shards of the short link and of its origin.
+func (s *shardedLinks) remove(short string, l *link) {
+	delete(s.shardFor(short).links, short)
+	origins := s.shardFor(l.origin).origins
+	if origins[l.origin] == short {
+		delete(origins, l.origin)
+	}
+}
+
+// setOrigin points a link at a new origin and moves its origin index entry.
+// The caller must hold the locks of the shards of the short link and of both origins.
+func (s *shardedLinks) setOrigin(short string, l *link, origin string) {
+	previous := s.shardFor(l.origin).origins
+	if previous[l.origin] == short {
+		delete(previous, l.origin)
+	}
+	s.shardFor(origin).origins[origin] = short
+	l.origin = origin
+}
+
+// lock acquires write locks on every shard responsible for the given keys and returns the release function.
+// Shards are locked in ascending order so that concurrent multi-shard operations cannot deadlock.
+func (s *shardedLinks) lock(keys []string) func() {
+	seen := make(map[int]bool, len(keys))
+	indexes := make([]int, 0, len(keys))
+	for _, key := range keys {
+		i := shardIndex(key)
+		if !seen[i] {
+			seen[i] = true
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+
+	for _, i := range indexes {
+		s.shards[i].mu.Lock()
+	}
+	return func() {
+		for j := len(indexes) - 1; j >= 0; j-- {
+			s.shards[indexes[j]].mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,521 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"main/internal/constants"
+	"main/internal/models"
+	"main/internal/services"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UsersRepository manages user registration, API keys, link retrieval and deletion in the in-memory database.
+type UsersRepository struct {
+	db *InMemoryDB // Pointer to the in-memory database instance.
+}
+
+// NewUsersRepository creates a new instance of UsersRepository bound to a specific InMemoryDB.
+func NewUsersRepository(db *InMemoryDB) *UsersRepository {
+	return &UsersRepository{
+		db: db,
+	}
+}
+
+// Login registers a new user, persists the registration and returns the assigned user ID.
+func (r *UsersRepository) Login(ctx context.Context) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	default:
+		userID := r.db.lastUserID.Add(1)
+
+		event := &models.Event{
+			ID:     r.db.nextEventID(),
+			Type:   models.UserAddedEvent,
+			UserID: userID,
+		}
+		if err := r.db.producerFS.WriteEvent(event); err != nil {
+			return -1, err
+		}
+		return userID, nil
+	}
+}
+
+// GetLinks fetches the links created by the current user that match the query, sorted and paginated as requested.
+func (r *UsersRepository) GetLinks(ctx context.Context, query models.LinksQuery) ([]models.UserLinks, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		userID := userIDFromContext(ctx)
+
+		r.db.usersMu.RLock()
+		shorts := append([]string(nil), r.db.userLinks[userID]...)
+		r.db.usersMu.RUnlock()
+
+		origin := strings.ToLower(query.Origin)
+
+		var links []models.UserLinks
+		for _, short := range shorts {
+			l, ok := r.db.links.get(short)
+			if !ok || l.deleted != query.Deleted {
+				continue
+			}
+			if origin != "" && !strings.Contains(strings.ToLower(l.origin), origin) {
+				continue
+			}
+			if !query.CreatedAfter.IsZero() && !l.createdAt.After(query.CreatedAfter) {
+				continue
+			}
+			links = append(links, models.UserLinks{
+				Shorten:   short,
+				Original:  l.origin,
+				CreatedAt: l.createdAt,
+			})
+		}
+
+		if query.SortBy == constants.LinksSortClicks {
+			r.db.clicksMu.RLock()
+			for i := range links {
+				links[i].Clicks = int64(len(r.db.clicks[links[i].Shorten]))
+			}
+			r.db.clicksMu.RUnlock()
+		}
+
+		sort.Slice(links, func(i, j int) bool {
+			return compareUserLinks(links[i], links[j], query) < 0
+		})
+		if query.After != nil {
+			after := models.UserLinks{
+				Shorten:   query.After.Short,
+				CreatedAt: query.After.CreatedAt,
+				Clicks:    query.After.Clicks,
+			}
+			links = links[sort.Search(len(links), func(i int) bool {
+				return compareUserLinks(links[i], after, query) > 0
+			}):]
+		}
+		if query.Limit > 0 && len(links) > query.Limit {
+			links = links[:query.Limit]
+		}
+
+		if len(links) == 0 {
+			return nil, services.ErrNoLinksByUser
+		}
+		return links, nil
+	}
+}
+
+// ExportLinks passes all links created by the current user, including soft-deleted ones, to yield one at a time
+// in creation order. It stops at the first error returned by yield.
+func (r *UsersRepository) ExportLinks(ctx context.Context, yield func(models.ExportedLink) error) error {
+	userID := userIDFromContext(ctx)
+
+	r.db.usersMu.RLock()
+	shorts := append([]string(nil), r.db.userLinks[userID]...)
+	r.db.usersMu.RUnlock()
+
+	for _, short := range shorts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		l, ok := r.db.links.get(short)
+		if !ok {
+			continue
+		}
+
+		r.db.clicksMu.RLock()
+		clicks := len(r.db.clicks[short])
+		r.db.clicksMu.RUnlock()
+
+		err := yield(models.ExportedLink{
+			Short:     short,
+			Original:  l.origin,
+			CreatedAt: l.createdAt,
+			Deleted:   l.deleted,
+			Clicks:    int64(clicks),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteLinks soft-deletes a batch of links requested by possibly different users and persists the deletions.
+// Links that do not exist, belong to users other than the requester or are already deleted are skipped.
+func (r *UsersRepository) DeleteLinks(ctx context.Context, deletions []models.LinkDeletion) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		shortLinks := make([]string, len(deletions))
+		for i, deletion := range deletions {
+			shortLinks[i] = deletion.Short
+		}
+		unlock := r.db.links.lock(shortLinks)
+		defer unlock()
+
+		for _, deletion := range deletions {
+			short, userID := deletion.Short, deletion.UserID
+			l, ok := r.db.links.shardFor(short).links[short]
+			if !ok || l.userID != userID || l.deleted {
+				continue
+			}
+			deletedAt := time.Now().UTC()
+
+			event := &models.Event{
+				ID:        r.db.nextEventID(),
+				Type:      models.LinkDeletedEvent,
+				Short:     short,
+				UserID:    userID,
+				DeletedAt: &deletedAt,
+			}
+			if err := r.db.producerFS.WriteEvent(event); err != nil {
+				return err
+			}
+			l.deleted, l.deletedAt = true, deletedAt
+		}
+		return nil
+	}
+}
+
+// RestoreLinks undoes the soft-deletion of the given links owned by the current user, persists the restorations
+// and returns how many links were restored. Links that are not deleted, belong to other users or were purged are skipped.
+func (r *UsersRepository) RestoreLinks(ctx context.Context, shortLinks []string) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		userID := userIDFromContext(ctx)
+
+		unlock := r.db.links.lock(shortLinks)
+		defer unlock()
+
+		var restored int64
+		for _, short := range shortLinks {
+			l, ok := r.db.links.shardFor(short).links[short]
+			if !ok || l.userID != userID || !l.deleted {
+				continue
+			}
+
+			event := &models.Event{
+				ID:     r.db.nextEventID(),
+				Type:   models.LinkRestoredEvent,
+				Short:  short,
+				UserID: userID,
+			}
+			if err := r.db.producerFS.WriteEvent(event); err != nil {
+				return restored, err
+			}
+			l.deleted, l.deletedAt = false, time.Time{}
+			restored++
+		}
+		return restored, nil
+	}
+}
+
+// AddAPIKey stores a new API key owned by the current user and persists it.
+func (r *UsersRepository) AddAPIKey(ctx context.Context, key models.APIKey) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		key.UserID = userIDFromContext(ctx)
+
+		r.db.usersMu.Lock()
+		defer r.db.usersMu.Unlock()
+
+		event := &models.Event{
+			ID:     r.db.nextEventID(),
+			Type:   models.APIKeyAddedEvent,
+			UserID: key.UserID,
+			APIKey: &key,
+		}
+		if err := r.db.producerFS.WriteEvent(event); err != nil {
+			return err
+		}
+		r.db.apiKeys[key.Hash] = key
+		return nil
+	}
+}
+
+// GetAPIKeys returns the API keys owned by the current user, oldest first.
+func (r *UsersRepository) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		userID := userIDFromContext(ctx)
+
+		r.db.usersMu.RLock()
+		defer r.db.usersMu.RUnlock()
+
+		var keys []models.APIKey
+		for _, key := range r.db.apiKeys {
+			if key.UserID == userID {
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		})
+		return keys, nil
+	}
+}
+
+// DeleteAPIKey revokes an API key owned by the current user and persists the revocation.
+// It returns services.ErrAPIKeyNotFound if the user has no key with the given ID.
+func (r *UsersRepository) DeleteAPIKey(ctx context.Context, id string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		userID := userIDFromContext(ctx)
+
+		r.db.usersMu.Lock()
+		defer r.db.usersMu.Unlock()
+
+		for hash, key := range r.db.apiKeys {
+			if key.ID != id || key.UserID != userID {
+				continue
+			}
+			event := &models.Event{
+				ID:     r.db.nextEventID(),
+				Type:   models.APIKeyRevokedEvent,
+				UserID: userID,
+				APIKey: &models.APIKey{ID: key.ID, Hash: hash},
+			}
+			if err := r.db.producerFS.WriteEvent(event); err != nil {
+				return err
+			}
+			delete(r.db.apiKeys, hash)
+			return nil
+		}
+		return services.ErrAPIKeyNotFound
+	}
+}
+
+// GetUserByAPIKey resolves the owner of an API key by the hash of the key.
+func (r *UsersRepository) GetUserByAPIKey(ctx context.Context, hash string) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		r.db.usersMu.RLock()
+		defer r.db.usersMu.RUnlock()
+
+		key, ok := r.db.apiKeys[hash]
+		if !ok {
+			return 0, services.ErrAPIKeyNotFound
+		}
+		return key.UserID, nil
+	}
+}
+
+// AddRefreshToken stores a new refresh token and persists it.
+func (r *UsersRepository) AddRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		r.db.usersMu.Lock()
+		defer r.db.usersMu.Unlock()
+
+		if err := r.writeSessionEvent(models.SessionSavedEvent, token); err != nil {
+			return err
+		}
+		r.db.sessions[token.Hash] = token
+		return nil
+	}
+}
+
+// ExtendRefreshToken moves the expiry of an unexpired refresh token and returns its owner.
+// It returns services.ErrSessionNotFound if the token is unknown or expired.
+func (r *UsersRepository) ExtendRefreshToken(ctx context.Context, hash string, expiresAt time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		r.db.usersMu.Lock()
+		defer r.db.usersMu.Unlock()
+
+		token, ok := r.db.sessions[hash]
+		if !ok || !token.ExpiresAt.After(time.Now()) {
+			return 0, services.ErrSessionNotFound
+		}
+		token.ExpiresAt = expiresAt
+		if err := r.writeSessionEvent(models.SessionSavedEvent, token); err != nil {
+			return 0, err
+		}
+		r.db.sessions[hash] = token
+		return token.UserID, nil
+	}
+}
+
+// DeleteRefreshToken revokes a refresh token and persists the revocation. Unknown tokens are ignored.
+func (r *UsersRepository) DeleteRefreshToken(ctx context.Context, hash string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		r.db.usersMu.Lock()
+		defer r.db.usersMu.Unlock()
+
+		token, ok := r.db.sessions[hash]
+		if !ok {
+			return nil
+		}
+		if err := r.writeSessionEvent(models.SessionDeletedEvent, token); err != nil {
+			return err
+		}
+		delete(r.db.sessions, hash)
+		return nil
+	}
+}
+
+// writeSessionEvent persists a change of a refresh token.
+func (r *UsersRepository) writeSessionEvent(eventType string, token models.RefreshToken) error {
+	event := &models.Event{
+		ID:      r.db.nextEventID(),
+		Type:    eventType,
+		UserID:  token.UserID,
+		Session: &token,
+	}
+	return r.db.producerFS.WriteEvent(event)
+}
+
+// GetUser retrieves a user by ID; anonymous users have no username or password hash.
+// It returns services.ErrUserNotFound if there is no such user.
+func (r *UsersRepository) GetUser(ctx context.Context, userID int64) (models.User, error) {
+	select {
+	case <-ctx.Done():
+		return models.User{}, ctx.Err()
+	default:
+		r.db.usersMu.RLock()
+		defer r.db.usersMu.RUnlock()
+
+		if user, ok := r.db.accounts[userID]; ok {
+			return user, nil
+		}
+		if userID <= 0 || userID > r.db.lastUserID.Load() {
+			return models.User{}, services.ErrUserNotFound
+		}
+		return models.User{ID: userID}, nil
+	}
+}
+
+// GetUserByUsername retrieves a registered user by username.
+// It returns services.ErrUserNotFound if there is no such user.
+func (r *UsersRepository) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	select {
+	case <-ctx.Done():
+		return models.User{}, ctx.Err()
+	default:
+		r.db.usersMu.RLock()
+		defer r.db.usersMu.RUnlock()
+
+		userID, ok := r.db.usernames[username]
+		if !ok {
+			return models.User{}, services.ErrUserNotFound
+		}
+		return r.db.accounts[userID], nil
+	}
+}
+
+// SetCredentials assigns a username and password hash to an anonymous user and persists the account.
+// It returns services.ErrUsernameTaken if the username belongs to another user
+// and services.ErrUserNotFound if the user does not exist or is already registered.
+func (r *UsersRepository) SetCredentials(ctx context.Context, userID int64, username, passwordHash string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		r.db.usersMu.Lock()
+		defer r.db.usersMu.Unlock()
+
+		if _, ok := r.db.usernames[username]; ok {
+			return services.ErrUsernameTaken
+		}
+		if _, ok := r.db.accounts[userID]; ok || userID <= 0 || userID > r.db.lastUserID.Load() {
+			return services.ErrUserNotFound
+		}
+
+		account := models.User{
+			ID:           userID,
+			Username:     username,
+			PasswordHash: passwordHash,
+		}
+		event := &models.Event{
+			ID:      r.db.nextEventID(),
+			Type:    models.AccountAddedEvent,
+			UserID:  userID,
+			Account: &account,
+		}
+		if err := r.db.producerFS.WriteEvent(event); err != nil {
+			return err
+		}
+		r.db.accounts[userID] = account
+		r.db.usernames[username] = userID
+		return nil
+	}
+}
+
+// ClaimLinks moves all links of one user to another, persists the transfer and returns the number of links moved.
+func (r *UsersRepository) ClaimLinks(ctx context.Context, fromUserID, toUserID int64) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		r.db.usersMu.RLock()
+		shorts := append([]string(nil), r.db.userLinks[fromUserID]...)
+		r.db.usersMu.RUnlock()
+		if len(shorts) == 0 {
+			return 0, nil
+		}
+
+		// Links are locked before the users map, in the same order as when links are added.
+		unlock := r.db.links.lock(shorts)
+		defer unlock()
+		r.db.usersMu.Lock()
+		defer r.db.usersMu.Unlock()
+
+		// Only links still owned by the anonymous user are moved; links added after the snapshot stay with it.
+		claimed := r.db.claimableLinks(fromUserID, shorts)
+		if len(claimed) == 0 {
+			return 0, nil
+		}
+
+		event := &models.Event{
+			ID:       r.db.nextEventID(),
+			Type:     models.LinksClaimedEvent,
+			UserID:   toUserID,
+			FromUser: fromUserID,
+		}
+		if err := r.db.producerFS.WriteEvent(event); err != nil {
+			return 0, err
+		}
+		r.db.moveUserLinks(fromUserID, toUserID, claimed)
+		return int64(len(claimed)), nil
+	}
+}
+
+// compareUserLinks orders two links by the sort key of the query, breaking ties by the short link.
+// It returns a negative number if a comes first in the listing, a positive one if b does and zero if they are equal.
+func compareUserLinks(a, b models.UserLinks, query models.LinksQuery) int {
+	var result int
+	if query.SortBy == constants.LinksSortClicks {
+		result = cmp.Compare(a.Clicks, b.Clicks)
+	} else {
+		result = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if result == 0 {
+		result = strings.Compare(a.Shorten, b.Shorten)
+	}
+	if query.Descending {
+		return -result
+	}
+	return result
+}
@@ -0,0 +1,336 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"main/internal/adapters"
+	"main/internal/constants"
+	"main/internal/models"
+	"main/internal/services"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsersRepositoryAPIKeys(t *testing.T) {
+	logger := adapters.GetLogger()
+	path := filepath.Join(t.TempDir(), "keys.jsonl")
+
+	db, err := NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	repo := NewUsersRepository(db)
+
+	userID, err := repo.Login(context.Background())
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), constants.UserIDKey, userID)
+
+	for _, key := range []models.APIKey{
+		{ID: "1", Name: "ci", Hash: "hash-1", CreatedAt: time.Now()},
+		{ID: "2", Name: "backend", Hash: "hash-2", CreatedAt: time.Now().Add(time.Second)},
+	} {
+		require.NoError(t, repo.AddAPIKey(ctx, key))
+	}
+
+	owner, err := repo.GetUserByAPIKey(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, userID, owner)
+
+	otherCtx := context.WithValue(context.Background(), constants.UserIDKey, userID+1)
+	assert.ErrorIs(t, repo.DeleteAPIKey(otherCtx, "1"), services.ErrAPIKeyNotFound)
+	require.NoError(t, repo.DeleteAPIKey(ctx, "1"))
+	require.NoError(t, db.Close())
+
+	db, err = NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewUsersRepository(db)
+
+	_, err = repo.GetUserByAPIKey(ctx, "hash-1")
+	assert.ErrorIs(t, err, services.ErrAPIKeyNotFound)
+
+	keys, err := repo.GetAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "backend", keys[0].Name)
+	assert.Equal(t, userID, keys[0].UserID)
+}
+
+func TestUsersRepositoryAccounts(t *testing.T) {
+	logger := adapters.GetLogger()
+	path := filepath.Join(t.TempDir(), "accounts.jsonl")
+
+	db, err := NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	repo := NewUsersRepository(db)
+	links := NewLinksRepository(db)
+
+	accountID, err := repo.Login(context.Background())
+	require.NoError(t, err)
+	anonymousID, err := repo.Login(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, repo.SetCredentials(context.Background(), accountID, "alice", "hash"))
+	assert.ErrorIs(t, repo.SetCredentials(context.Background(), anonymousID, "alice", "hash"), services.ErrUsernameTaken)
+	assert.ErrorIs(t, repo.SetCredentials(context.Background(), accountID, "bob", "hash"), services.ErrUserNotFound)
+	assert.ErrorIs(t, repo.SetCredentials(context.Background(), anonymousID+1, "bob", "hash"), services.ErrUserNotFound)
+
+	anonymousCtx := context.WithValue(context.Background(), constants.UserIDKey, anonymousID)
+	for _, short := range []string{"a", "b"} {
+		_, err := links.Add(anonymousCtx, models.AddedLink{Short: short, Origin: "https://" + short + ".example"})
+		require.NoError(t, err)
+	}
+
+	claimed, err := repo.ClaimLinks(context.Background(), anonymousID, accountID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), claimed)
+	require.NoError(t, db.Close())
+
+	db, err = NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewUsersRepository(db)
+
+	account, err := repo.GetUserByUsername(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.User{ID: accountID, Username: "alice", PasswordHash: "hash"}, account)
+
+	anonymous, err := repo.GetUser(context.Background(), anonymousID)
+	require.NoError(t, err)
+	assert.Empty(t, anonymous.Username)
+
+	accountLinks, err := repo.GetLinks(context.WithValue(context.Background(), constants.UserIDKey, accountID), models.LinksQuery{})
+	require.NoError(t, err)
+	assert.Len(t, accountLinks, 2)
+	_, err = repo.GetLinks(anonymousCtx, models.LinksQuery{})
+	assert.ErrorIs(t, err, services.ErrNoLinksByUser)
+}
+
+func TestUsersRepositoryClaimLinksStaleEntries(t *testing.T) {
+	logger := adapters.GetLogger()
+	ctx := context.Background()
+
+	db, err := NewInMemoryDB(filepath.Join(t.TempDir(), "claims.jsonl"), logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewUsersRepository(db)
+	links := NewLinksRepository(db)
+
+	accountID, err := repo.Login(ctx)
+	require.NoError(t, err)
+	anonymousID, err := repo.Login(ctx)
+	require.NoError(t, err)
+
+	anonymousCtx := context.WithValue(ctx, constants.UserIDKey, anonymousID)
+	for _, short := range []string{"a", "b"} {
+		_, err := links.Add(anonymousCtx, models.AddedLink{Short: short, Origin: "https://" + short + ".example"})
+		require.NoError(t, err)
+	}
+	// Lists written by earlier versions may still name links removed since.
+	db.userLinks[anonymousID] = append([]string{"gone"}, db.userLinks[anonymousID]...)
+
+	claimed, err := repo.ClaimLinks(ctx, anonymousID, accountID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), claimed)
+	assert.Equal(t, []string{"a", "b"}, db.userLinks[accountID])
+	assert.Equal(t, []string{"gone"}, db.userLinks[anonymousID], "only the claimed links leave the list")
+}
+
+func TestUsersRepositorySessions(t *testing.T) {
+	logger := adapters.GetLogger()
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	db, err := NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	repo := NewUsersRepository(db)
+
+	require.NoError(t, repo.AddRefreshToken(ctx, models.RefreshToken{Hash: "active", UserID: 1, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repo.AddRefreshToken(ctx, models.RefreshToken{Hash: "expired", UserID: 1, ExpiresAt: now.Add(-time.Second)}))
+	require.NoError(t, repo.AddRefreshToken(ctx, models.RefreshToken{Hash: "revoked", UserID: 2, ExpiresAt: now.Add(time.Hour)}))
+
+	extendedUntil := now.Add(30 * 24 * time.Hour)
+	userID, err := repo.ExtendRefreshToken(ctx, "active", extendedUntil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), userID)
+
+	_, err = repo.ExtendRefreshToken(ctx, "expired", extendedUntil)
+	assert.ErrorIs(t, err, services.ErrSessionNotFound, "expired sessions cannot be extended")
+	_, err = repo.ExtendRefreshToken(ctx, "unknown", extendedUntil)
+	assert.ErrorIs(t, err, services.ErrSessionNotFound)
+
+	require.NoError(t, repo.DeleteRefreshToken(ctx, "revoked"))
+	require.NoError(t, repo.DeleteRefreshToken(ctx, "unknown"), "unknown tokens are ignored")
+	require.NoError(t, db.Close())
+
+	db, err = NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewUsersRepository(db)
+
+	assert.True(t, db.sessions["active"].ExpiresAt.Equal(extendedUntil), "extensions survive a restart")
+	_, err = repo.ExtendRefreshToken(ctx, "active", extendedUntil.Add(time.Hour))
+	assert.NoError(t, err)
+	_, err = repo.ExtendRefreshToken(ctx, "revoked", extendedUntil)
+	assert.ErrorIs(t, err, services.ErrSessionNotFound, "revocations survive a restart")
+}
+
+func TestUsersRepositoryRestoreAndPurge(t *testing.T) {
+	logger := adapters.GetLogger()
+	path := filepath.Join(t.TempDir(), "restore.jsonl")
+	ctx := context.WithValue(context.Background(), constants.UserIDKey, int64(1))
+
+	db, err := NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	repo := NewUsersRepository(db)
+	links := NewLinksRepository(db)
+
+	for _, short := range []string{"kept", "restored", "purged"} {
+		_, err := links.Add(ctx, models.AddedLink{Short: short, Origin: "https://" + short + ".example"})
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.DeleteLinks(ctx, []models.LinkDeletion{
+		{Short: "restored", UserID: 1},
+		{Short: "purged", UserID: 1},
+		{Short: "kept", UserID: 2},
+	}))
+
+	deleted, err := repo.GetLinks(ctx, models.LinksQuery{Deleted: true})
+	require.NoError(t, err)
+	assert.Len(t, deleted, 2)
+
+	restored, err := repo.RestoreLinks(ctx, []string{"restored", "kept", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), restored)
+
+	purged, err := links.DeleteSoftDeleted(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	purged, err = links.DeleteSoftDeleted(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	require.NoError(t, db.Close())
+
+	db, err = NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewUsersRepository(db)
+	links = NewLinksRepository(db)
+
+	active, err := repo.GetLinks(ctx, models.LinksQuery{})
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.ElementsMatch(t, []string{"kept", "restored"}, []string{active[0].Shorten, active[1].Shorten})
+	_, err = repo.GetLinks(ctx, models.LinksQuery{Deleted: true})
+	assert.ErrorIs(t, err, services.ErrNoLinksByUser)
+
+	_, err = links.Add(ctx, models.AddedLink{Short: "again", Origin: "https://purged.example"})
+	assert.NoError(t, err)
+}
+
+func TestUsersRepositoryGetLinksQuery(t *testing.T) {
+	logger := adapters.GetLogger()
+	path := filepath.Join(t.TempDir(), "query.jsonl")
+	ctx := context.WithValue(context.Background(), constants.UserIDKey, int64(1))
+
+	db, err := NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	repo := NewUsersRepository(db)
+	links := NewLinksRepository(db)
+
+	for _, short := range []string{"a", "b", "c", "d"} {
+		_, err := links.Add(ctx, models.AddedLink{Short: short, Origin: "https://" + short + ".Example.com"})
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+	require.NoError(t, db.Close())
+
+	db, err = NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewUsersRepository(db)
+	clicks := NewClicksRepository(db)
+	require.NoError(t, clicks.AddBatch(ctx, []models.Click{{Short: "c"}, {Short: "c"}, {Short: "a"}}))
+
+	shorts := func(links []models.UserLinks) []string {
+		var result []string
+		for _, link := range links {
+			result = append(result, link.Shorten)
+		}
+		return result
+	}
+
+	page, err := repo.GetLinks(ctx, models.LinksQuery{SortBy: constants.LinksSortCreated, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, shorts(page))
+
+	page, err = repo.GetLinks(ctx, models.LinksQuery{
+		SortBy: constants.LinksSortCreated,
+		Limit:  2,
+		After:  &models.LinksCursor{Short: page[1].Shorten, CreatedAt: page[1].CreatedAt},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, shorts(page))
+
+	page, err = repo.GetLinks(ctx, models.LinksQuery{SortBy: constants.LinksSortClicks, Descending: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "a", "d", "b"}, shorts(page))
+	assert.Equal(t, int64(2), page[0].Clicks)
+
+	page, err = repo.GetLinks(ctx, models.LinksQuery{
+		SortBy:     constants.LinksSortClicks,
+		Descending: true,
+		After:      &models.LinksCursor{Short: "a", Clicks: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d", "b"}, shorts(page))
+
+	page, err = repo.GetLinks(ctx, models.LinksQuery{Origin: "C.EXAMPLE"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, shorts(page))
+
+	_, err = repo.GetLinks(ctx, models.LinksQuery{CreatedAfter: time.Now()})
+	assert.ErrorIs(t, err, services.ErrNoLinksByUser)
+}
+
+func TestUsersRepositoryExportLinks(t *testing.T) {
+	logger := adapters.GetLogger()
+	path := filepath.Join(t.TempDir(), "export.jsonl")
+	ctx := context.WithValue(context.Background(), constants.UserIDKey, int64(1))
+
+	db, err := NewInMemoryDB(path, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewUsersRepository(db)
+	links := NewLinksRepository(db)
+	clicks := NewClicksRepository(db)
+
+	for _, short := range []string{"first", "second"} {
+		_, err := links.Add(ctx, models.AddedLink{Short: short, Origin: "https://" + short + ".example"})
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.DeleteLinks(ctx, []models.LinkDeletion{{Short: "second", UserID: 1}}))
+	require.NoError(t, clicks.AddBatch(ctx, []models.Click{{Short: "first"}}))
+
+	var exported []models.ExportedLink
+	require.NoError(t, repo.ExportLinks(ctx, func(link models.ExportedLink) error {
+		link.CreatedAt = time.Time{}
+		exported = append(exported, link)
+		return nil
+	}))
+	assert.Equal(t, []models.ExportedLink{
+		{Short: "first", Original: "https://first.example", Clicks: 1},
+		{Short: "second", Original: "https://second.example", Deleted: true},
+	}, exported)
+
+	stop := errors.New("stop")
+	calls := 0
+	assert.ErrorIs(t, repo.ExportLinks(ctx, func(models.ExportedLink) error {
+		calls++
+		return stop
+	}), stop)
+	assert.Equal(t, 1, calls)
+}
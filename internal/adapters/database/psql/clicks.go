@@ -0,0 +1,83 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"main/internal/constants"
+	"main/internal/models"
+	"main/internal/services"
+	"time"
+)
+
+// ClicksRepository manages click events stored in a PostgreSQL database.
+type ClicksRepository struct {
+	db *PostgresDB // Reference to the PostgreSQL database handler.
+}
+
+// NewClicksRepository constructs a new ClicksRepository instance connected to a specific PostgresDB.
+func NewClicksRepository(db *PostgresDB) *ClicksRepository {
+	return &ClicksRepository{
+		db: db,
+	}
+}
+
+// AddBatch stores multiple clicks with a single multi-row insert.
+func (r *ClicksRepository) AddBatch(ctx context.Context, clicks []models.Click) error {
+	shorts := make([]string, len(clicks))
+	clickedAt := make([]time.Time, len(clicks))
+	referrers := make([]string, len(clicks))
+	userAgents := make([]string, len(clicks))
+	ipHashes := make([]string, len(clicks))
+
+	for i, click := range clicks {
+		shorts[i] = click.Short
+		clickedAt[i] = click.ClickedAt
+		referrers[i] = click.Referrer
+		userAgents[i] = click.UserAgent
+		ipHashes[i] = click.IPHash
+	}
+
+	_, err := r.db.Connection.ExecContext(ctx, addClicks, shorts, clickedAt, referrers, userAgents, ipHashes)
+	if err != nil {
+		return fmt.Errorf("couldn't add clicks: %w", err)
+	}
+	return nil
+}
+
+// GetStats aggregates the clicks of a short link owned by the current user into per-day buckets.
+func (r *ClicksRepository) GetStats(ctx context.Context, short string) (models.LinkStats, error) {
+	userID := ctx.Value(constants.UserIDKey).(int64)
+
+	var owned int
+	err := r.db.Connection.QueryRowContext(ctx, getLinkOwner, short, userID).Scan(&owned)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.LinkStats{}, services.ErrLinkNotFound
+	} else if err != nil {
+		return models.LinkStats{}, err
+	}
+
+	rows, err := r.db.Connection.QueryContext(ctx, getDailyClicks, short)
+	if err != nil {
+		return models.LinkStats{}, fmt.Errorf("couldn't get link clicks: %w", err)
+	}
+	defer rows.Close()
+
+	stats := models.LinkStats{
+		Short: short,
+	}
+	for rows.Next() {
+		var daily models.DailyClicks
+		if err := rows.Scan(&daily.Day, &daily.Clicks); err != nil {
+			return models.LinkStats{}, err
+		}
+		stats.Total += daily.Clicks
+		stats.Daily = append(stats.Daily, daily)
+	}
+	if err := rows.Err(); err != nil {
+		return models.LinkStats{}, err
+	}
+	return stats, nil
+}
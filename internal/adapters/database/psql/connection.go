@@ -5,8 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 	"go.uber.org/zap"
+	"main/internal/constants"
 	"net/url"
-	"time"
 )
 
 // PostgresDB encapsulates a SQL database connection for interacting with a PostgreSQL backend.
@@ -29,11 +29,31 @@ func (p *PostgresDB) Ping() error {
 	return err
 }
 
-// NewPostgresDB establishes a new PostgreSQL database connection using provided credentials.
+// NewPostgresDB establishes a new PostgreSQL database connection using provided credentials
+// and applies pending schema migrations.
 func NewPostgresDB(PostgresDSN *url.URL, logger *zap.SugaredLogger) (*PostgresDB, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), constants.MigrationsTimeout)
 	defer cancel()
 
+	postgresDB, err := OpenPostgresDB(PostgresDSN, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := MigrateUp(ctx, postgresDB)
+	if err != nil {
+		logger.Infow("Error during schema migration", "error", err.Error())
+		postgresDB.Close()
+		return nil, err
+	}
+	if len(applied) > 0 {
+		logger.Infow("Schema migrations applied", "versions", applied)
+	}
+	return postgresDB, nil
+}
+
+// OpenPostgresDB establishes a new PostgreSQL database connection using provided credentials without migrating the schema.
+func OpenPostgresDB(PostgresDSN *url.URL, logger *zap.SugaredLogger) (*PostgresDB, error) {
 	host := PostgresDSN.Hostname()
 	port := PostgresDSN.Port()
 	user := PostgresDSN.User.Username()
@@ -49,23 +69,8 @@ func NewPostgresDB(PostgresDSN *url.URL, logger *zap.SugaredLogger) (*PostgresDB
 		return nil, err
 	}
 
-	err = migrate(ctx, conn)
-	if err != nil {
-		logger.Infow("Error during table creation", "error", err.Error())
-		return nil, err
-	}
-
 	postgresDB := PostgresDB{
 		Connection: conn,
 	}
 	return &postgresDB, nil
 }
-
-// migrate applies database schema migrations using the provided context and connection.
-func migrate(ctx context.Context, conn *sql.DB) error {
-	_, err := conn.ExecContext(ctx, createTables)
-	if err != nil {
-		return err
-	}
-	return nil
-}
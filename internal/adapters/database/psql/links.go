@@ -11,6 +11,7 @@ import (
 	"main/internal/constants"
 	"main/internal/models"
 	"main/internal/services"
+	"time"
 )
 
 // LinksRepository manages CRUD operations for links stored in a PostgreSQL database.
@@ -29,7 +30,7 @@ func NewLinksRepository(db *PostgresDB) *LinksRepository {
 func (r *LinksRepository) Add(ctx context.Context, addedLink models.AddedLink) (string, error) {
 	userID := ctx.Value(constants.UserIDKey).(int64)
 
-	_, err := r.db.Connection.ExecContext(ctx, addShortLink, addedLink.Short, addedLink.Origin, userID)
+	_, err := r.db.Connection.ExecContext(ctx, addShortLink, addedLink.Short, addedLink.Origin, userID, nullTime(addedLink.ExpiresAt))
 	if err == nil {
 		return addedLink.Short, nil
 	}
@@ -38,6 +39,9 @@ func (r *LinksRepository) Add(ctx context.Context, addedLink models.AddedLink) (
 	if !errors.As(err, &pgErr) || !pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
 		return "", err
 	}
+	if isShortTaken(pgErr) {
+		return "", services.ErrShortLinkTaken
+	}
 
 	var shortLink string
 	err = r.db.Connection.QueryRowContext(ctx, getOrigin, addedLink.Origin).Scan(&shortLink)
@@ -48,46 +52,267 @@ func (r *LinksRepository) Add(ctx context.Context, addedLink models.AddedLink) (
 	return shortLink, services.ErrConflict
 }
 
-// AddBatch bulk-adds multiple links atomically using transactions.
+// AddBatch bulk-adds multiple links atomically with a single multi-row insert inside a transaction.
+// Items whose origin has already been shortened, including earlier in the same batch,
+// are reported with the existing short link instead of being added.
 func (r *LinksRepository) AddBatch(ctx context.Context, addedLinks []models.AddedLink) ([]models.Result, error) {
 	userID := ctx.Value(constants.UserIDKey).(int64)
 
-	tx, err := r.db.Connection.Begin()
+	shorts := make([]string, len(addedLinks))
+	origins := make([]string, len(addedLinks))
+	expiresAt := make([]*time.Time, len(addedLinks))
+
+	for i, link := range addedLinks {
+		shorts[i] = link.Short
+		origins[i] = link.Origin
+		if !link.ExpiresAt.IsZero() {
+			expiresAt[i] = &addedLinks[i].ExpiresAt
+		}
+	}
+
+	tx, err := r.db.Connection.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertShortLinks(ctx, tx, shorts, origins, expiresAt, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing map[string]string
+	if len(created) < len(addedLinks) {
+		existing, err = getShortsByOrigin(ctx, tx, origins)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.Result, len(addedLinks))
+	for i, link := range addedLinks {
+		results[i].CorrelationID = link.CorrelationID
+		if created[link.Short] {
+			results[i].Result, results[i].Status = link.Short, constants.LinkCreated
+		} else {
+			results[i].Result, results[i].Status = existing[link.Origin], constants.LinkExists
+		}
+	}
+	return results, nil
+}
+
+// Import adds links with preset short codes in a single transaction.
+// Items whose origin has already been shortened are reported as existing and items whose short code
+// is already in use, including earlier in the same batch, as taken instead of failing the batch.
+func (r *LinksRepository) Import(ctx context.Context, addedLinks []models.AddedLink) ([]models.Result, error) {
+	userID := ctx.Value(constants.UserIDKey).(int64)
+
+	shorts := make([]string, len(addedLinks))
+	origins := make([]string, len(addedLinks))
+	expiresAt := make([]*time.Time, len(addedLinks))
+
+	for i, link := range addedLinks {
+		shorts[i] = link.Short
+		origins[i] = link.Origin
+		if !link.ExpiresAt.IsZero() {
+			expiresAt[i] = &addedLinks[i].ExpiresAt
+		}
+	}
+
+	tx, err := r.db.Connection.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	var results []models.Result
+	created, err := insertImportedLinks(ctx, tx, shorts, origins, expiresAt, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, link := range addedLinks {
-		_, err := r.db.Connection.ExecContext(ctx, addShortLink, link.Short, link.Origin, userID)
+	var existing map[string]string
+	if len(created) < len(addedLinks) {
+		existing, err = getShortsByOrigin(ctx, tx, origins)
 		if err != nil {
-			tx.Rollback()
 			return nil, err
 		}
-		result := models.Result{
-			CorrelationID: link.CorrelationID,
-			Result:        link.Short,
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.Result, len(addedLinks))
+	for i, link := range addedLinks {
+		results[i].CorrelationID = link.CorrelationID
+		switch {
+		case created[link.Short] == link.Origin:
+			results[i].Result, results[i].Status = link.Short, constants.LinkCreated
+			delete(created, link.Short)
+		case existing[link.Origin] != "":
+			results[i].Result, results[i].Status = existing[link.Origin], constants.LinkExists
+		default:
+			results[i].Result, results[i].Status = link.Short, constants.LinkTaken
 		}
-		results = append(results, result)
 	}
-	tx.Commit()
 	return results, nil
 }
 
+// insertShortLinks inserts links whose origins are not shortened yet and returns the set of inserted short links.
+func insertShortLinks(ctx context.Context, tx *sql.Tx, shorts, origins []string, expiresAt []*time.Time, userID int64) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, addShortLinks, shorts, origins, expiresAt, userID)
+	if err != nil {
+		return nil, shortLinksError(err)
+	}
+	defer rows.Close()
+
+	created := make(map[string]bool, len(shorts))
+	for rows.Next() {
+		var short string
+		if err := rows.Scan(&short); err != nil {
+			return nil, err
+		}
+		created[short] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, shortLinksError(err)
+	}
+	return created, nil
+}
+
+// insertImportedLinks inserts links whose origins and short links are both free and maps the inserted short links to their origins.
+func insertImportedLinks(ctx context.Context, tx *sql.Tx, shorts, origins []string, expiresAt []*time.Time, userID int64) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, importShortLinks, shorts, origins, expiresAt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	created := make(map[string]string, len(shorts))
+	for rows.Next() {
+		var short, origin string
+		if err := rows.Scan(&short, &origin); err != nil {
+			return nil, err
+		}
+		created[short] = origin
+	}
+	return created, rows.Err()
+}
+
+// getShortsByOrigin maps the given origins to their stored short links.
+func getShortsByOrigin(ctx context.Context, tx *sql.Tx, origins []string) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, getShortsByOrigins, origins)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[string]string, len(origins))
+	for rows.Next() {
+		var origin, short string
+		if err := rows.Scan(&origin, &short); err != nil {
+			return nil, err
+		}
+		existing[origin] = short
+	}
+	return existing, rows.Err()
+}
+
+// shortLinksError maps a unique violation on the short link column to services.ErrShortLinkTaken.
+func shortLinksError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && isShortTaken(pgErr) {
+		return services.ErrShortLinkTaken
+	}
+	return err
+}
+
 // Get retrieves the original URL associated with a given short link.
+// Unknown, expired and deleted links are reported with services.ErrLinkNotFound, ErrExpiredLink and ErrDeletedLink.
 func (r *LinksRepository) Get(ctx context.Context, short string) (string, error) {
 	var originalLink string
 	var isDeleted bool
+	var expiresAt sql.NullTime
 
-	err := r.db.Connection.QueryRowContext(ctx, getShortLink, short).Scan(&originalLink, &isDeleted)
+	err := r.db.Connection.QueryRowContext(ctx, getShortLink, short).Scan(&originalLink, &isDeleted, &expiresAt)
 	if errors.Is(err, sql.ErrNoRows) {
-		return "", fmt.Errorf("link with short %s not found", short)
+		return "", fmt.Errorf("%w: short %s", services.ErrLinkNotFound, short)
 	} else if err != nil {
 		return "", err
 	}
+	if expiresAt.Valid && !expiresAt.Time.After(time.Now()) {
+		return "", services.ErrExpiredLink
+	}
 	if isDeleted {
 		return "", services.ErrDeletedLink
 	}
 	return originalLink, nil
 }
+
+// DeleteSoftDeleted permanently removes links soft-deleted before the given moment and returns how many were removed.
+func (r *LinksRepository) DeleteSoftDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.Connection.ExecContext(ctx, deleteSoftDeletedLinks, before)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't purge deleted links: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Update changes the destination of a link owned by the user and records the previous one in the link history.
+// It returns services.ErrLinkNotFound if the user has no such link or it is deleted
+// and services.ErrConflict if the new origin has already been shortened.
+func (r *LinksRepository) Update(ctx context.Context, short, origin string) error {
+	userID := ctx.Value(constants.UserIDKey).(int64)
+
+	tx, err := r.db.Connection.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't start a transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var previous string
+	err = tx.QueryRowContext(ctx, getOwnLinkForUpdate, short, userID).Scan(&previous)
+	if errors.Is(err, sql.ErrNoRows) {
+		return services.ErrLinkNotFound
+	} else if err != nil {
+		return err
+	}
+	if previous == origin {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, updateLinkOrigin, short, origin); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return services.ErrConflict
+		}
+		return fmt.Errorf("couldn't update link: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, addLinkHistory, short, origin, previous, userID); err != nil {
+		return fmt.Errorf("couldn't record link history: %w", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteExpired removes links that expired before the given moment and returns how many were removed.
+func (r *LinksRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.Connection.ExecContext(ctx, deleteExpiredLinks, before)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't delete expired links: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// isShortTaken reports whether a database error is a unique violation on the short link column.
+func isShortTaken(pgErr *pgconn.PgError) bool {
+	return pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == shortConstraint
+}
+
+// nullTime converts a zero time into a SQL NULL value.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
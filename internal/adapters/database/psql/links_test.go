@@ -0,0 +1,62 @@
+package psql
+
+import (
+	"context"
+	"main/internal/adapters"
+	"main/internal/constants"
+	"main/internal/models"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// BenchmarkAddBatch measures the throughput of adding 10k-link batches.
+// It requires a PostgreSQL instance given by the DATABASE_DSN environment variable.
+func BenchmarkAddBatch(b *testing.B) {
+	dsn := os.Getenv("DATABASE_DSN")
+	if dsn == "" {
+		b.Skip("DATABASE_DSN is not set")
+	}
+	const batchSize = 10000
+
+	logger := adapters.GetLogger()
+	postgresDSN, err := url.Parse(dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	db, err := NewPostgresDB(postgresDSN, logger)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	var userID int64
+	if err := db.Connection.QueryRow(addUser).Scan(&userID); err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), constants.UserIDKey, userID)
+	repo := NewLinksRepository(db)
+	prefix := strconv.FormatInt(userID, 10) + "-"
+
+	addedLinks := make([]models.AddedLink, batchSize)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for j := range addedLinks {
+			short := prefix + strconv.Itoa(i) + "-" + strconv.Itoa(j)
+			addedLinks[j] = models.AddedLink{
+				CorrelationID: strconv.Itoa(j),
+				Short:         short,
+				Origin:        "https://example.com/" + short,
+			}
+		}
+		b.StartTimer()
+
+		if _, err := repo.AddBatch(ctx, addedLinks); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*batchSize)/b.Elapsed().Seconds(), "links/s")
+}
@@ -0,0 +1,193 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFiles holds the versioned schema migrations named "<version>_<name>.<up|down>.sql".
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationsLockID identifies the advisory lock serializing concurrent migration runs.
+const migrationsLockID = 7_201_911
+
+// ErrInvalidMigration is returned when the embedded migration files are malformed.
+var ErrInvalidMigration = errors.New("invalid migration")
+
+// migration represents a single versioned schema change.
+type migration struct {
+	version int    // Sequential version number of the migration.
+	name    string // Human-readable name of the migration.
+	up      string // SQL applying the change.
+	down    string // SQL reverting the change.
+}
+
+// MigrateUp applies all pending migrations in ascending order and returns the versions applied.
+func MigrateUp(ctx context.Context, db *PostgresDB) ([]int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []int
+	err = withMigrationsLock(ctx, db.Connection, func(conn *sql.Conn) error {
+		done, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if done[m.version] {
+				continue
+			}
+			if err := runMigration(ctx, conn, m.up, addAppliedMigration, m.version, m.name); err != nil {
+				return fmt.Errorf("couldn't apply migration %04d_%s: %w", m.version, m.name, err)
+			}
+			applied = append(applied, m.version)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown reverts up to the given number of the most recently applied migrations and returns the versions reverted.
+func MigrateDown(ctx context.Context, db *PostgresDB, steps int) ([]int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []int
+	err = withMigrationsLock(ctx, db.Connection, func(conn *sql.Conn) error {
+		done, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			m := migrations[i]
+			if !done[m.version] {
+				continue
+			}
+			if err := runMigration(ctx, conn, m.down, deleteAppliedMigration, m.version); err != nil {
+				return fmt.Errorf("couldn't revert migration %04d_%s: %w", m.version, m.name, err)
+			}
+			reverted = append(reverted, m.version)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// withMigrationsLock runs fn on a dedicated connection holding the migrations advisory lock,
+// so that several instances starting at once do not apply the same migrations concurrently.
+func withMigrationsLock(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, lockMigrations, migrationsLockID); err != nil {
+		return fmt.Errorf("couldn't acquire migrations lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), unlockMigrations, migrationsLockID)
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// appliedMigrations returns the set of migration versions recorded as applied.
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int]bool, error) {
+	rows, err := conn.QueryContext(ctx, getAppliedMigrations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// runMigration executes a migration script together with its bookkeeping statement in a single transaction.
+func runMigration(ctx context.Context, conn *sql.Conn, script string, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// loadMigrations reads the embedded migration files and returns them sorted by version.
+func loadMigrations() ([]migration, error) {
+	files, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, file := range files {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(file.Name(), ".sql"), ".")
+		prefix, name, found := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || !found || err != nil {
+			return nil, fmt.Errorf("%w: unexpected file name %q", ErrInvalidMigration, file.Name())
+		}
+
+		script, err := migrationFiles.ReadFile(path.Join("migrations", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &migration{version: version, name: name}
+			byVersion[version] = m
+		}
+		switch direction {
+		case "up":
+			m.up = string(script)
+		case "down":
+			m.down = string(script)
+		default:
+			return nil, fmt.Errorf("%w: unexpected direction in %q", ErrInvalidMigration, file.Name())
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("%w: version %d must have both up and down scripts", ErrInvalidMigration, m.version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS events (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    origin VARCHAR(255) NOT NULL UNIQUE,
+    short VARCHAR(255) NOT NULL,
+    is_deleted BOOLEAN DEFAULT FALSE
+);
+ALTER TABLE events ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS origin_index ON events(origin);
+CREATE UNIQUE INDEX IF NOT EXISTS short_index ON events(short);
+
+CREATE TABLE IF NOT EXISTS clicks (
+    id BIGSERIAL PRIMARY KEY,
+    short VARCHAR(255) NOT NULL,
+    clicked_at TIMESTAMPTZ NOT NULL,
+    referrer TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_hash VARCHAR(64) NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS clicks_short_index ON clicks(short, clicked_at);
@@ -0,0 +1,2 @@
+ALTER TABLE events DROP COLUMN IF EXISTS created_at;
+ALTER TABLE events ALTER COLUMN origin TYPE VARCHAR(255);
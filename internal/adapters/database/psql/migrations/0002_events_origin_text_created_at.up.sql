@@ -0,0 +1,2 @@
+ALTER TABLE events ALTER COLUMN origin TYPE TEXT;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
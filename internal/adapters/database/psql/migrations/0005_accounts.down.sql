@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS events_user_index;
+ALTER TABLE users DROP COLUMN IF EXISTS password_hash;
+ALTER TABLE users DROP COLUMN IF EXISTS username;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS link_history (
+    id BIGSERIAL PRIMARY KEY,
+    short VARCHAR(255) NOT NULL,
+    origin TEXT NOT NULL,
+    previous_origin TEXT NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS link_history_short_index ON link_history(short, changed_at);
@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+UPDATE events SET deleted_at = now() WHERE is_deleted AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS events_deleted_at_index ON events(deleted_at) WHERE is_deleted;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS events_user_created_index;
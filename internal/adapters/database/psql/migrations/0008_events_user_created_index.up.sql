@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS events_user_created_index ON events(user_id, created_at, short);
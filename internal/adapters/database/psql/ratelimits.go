@@ -0,0 +1,42 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"main/internal/models"
+	"time"
+)
+
+// RateLimitRepository manages the token buckets of rate limits stored in a PostgreSQL database,
+// so that all instances of the service share the same limits.
+type RateLimitRepository struct {
+	db *PostgresDB // Reference to the PostgreSQL database handler.
+}
+
+// NewRateLimitRepository constructs a new RateLimitRepository instance connected to a specific PostgresDB.
+func NewRateLimitRepository(db *PostgresDB) *RateLimitRepository {
+	return &RateLimitRepository{
+		db: db,
+	}
+}
+
+// Take refills the bucket of the key and takes a token from it if there is one, in a single atomic upsert.
+// Unknown keys start with a full bucket.
+func (r *RateLimitRepository) Take(ctx context.Context, key string, limit models.RateLimit, now time.Time) (models.RateLimitBucket, error) {
+	var bucket models.RateLimitBucket
+
+	row := r.db.Connection.QueryRowContext(ctx, takeRateLimitToken, key, limit.Requests, limit.Rate(), now)
+	if err := row.Scan(&bucket.Tokens, &bucket.Allowed); err != nil {
+		return bucket, fmt.Errorf("couldn't take rate limit token: %w", err)
+	}
+	return bucket, nil
+}
+
+// DeleteIdle removes the buckets last used before the given moment and returns how many were removed.
+func (r *RateLimitRepository) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.Connection.ExecContext(ctx, deleteIdleRateLimits, before)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't delete idle rate limits: %w", err)
+	}
+	return res.RowsAffected()
+}
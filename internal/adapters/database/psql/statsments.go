@@ -1,35 +1,156 @@
 package psql
 
 const (
-	createTables = `
-		CREATE TABLE IF NOT EXISTS users (
-		    id SERIAL PRIMARY KEY
-		);
-		CREATE TABLE IF NOT EXISTS events (
-			id SERIAL PRIMARY KEY,
-			user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
-			origin VARCHAR(255) NOT NULL UNIQUE,
-			short VARCHAR(255) NOT NULL,
-			is_deleted BOOLEAN DEFAULT FALSE
-		);
-		CREATE INDEX IF NOT EXISTS origin_index ON events(origin);`
+	// Migrations
+	createMigrationsTable = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);`
+	lockMigrations = `
+		SELECT pg_advisory_lock($1);`
+	unlockMigrations = `
+		SELECT pg_advisory_unlock($1);`
+	getAppliedMigrations = `
+		SELECT version FROM schema_migrations ORDER BY version;`
+	addAppliedMigration = `
+		INSERT INTO schema_migrations (version, name) VALUES ($1, $2);`
+	deleteAppliedMigration = `
+		DELETE FROM schema_migrations WHERE version = $1;`
+	// Constraints
+	shortConstraint = "short_index"
 	// Links
 	addShortLink = `
-		INSERT INTO events (short, origin, user_id) 
-		VALUES ($1, $2, $3)`
+		INSERT INTO events (short, origin, user_id, expires_at) 
+		VALUES ($1, $2, $3, $4)`
+	addShortLinks = `
+		INSERT INTO events (short, origin, user_id, expires_at) 
+		SELECT short, origin, $4, expires_at 
+		FROM unnest($1::text[], $2::text[], $3::timestamptz[]) AS t(short, origin, expires_at) 
+		ON CONFLICT (origin) DO NOTHING 
+		RETURNING short;`
+	importShortLinks = `
+		INSERT INTO events (short, origin, user_id, expires_at) 
+		SELECT short, origin, $4, expires_at 
+		FROM unnest($1::text[], $2::text[], $3::timestamptz[]) AS t(short, origin, expires_at) 
+		ON CONFLICT DO NOTHING 
+		RETURNING short, origin;`
+	getShortsByOrigins = `
+		SELECT origin, short 
+		FROM events 
+		WHERE origin = ANY($1::text[]);`
 	getShortLink = `
-		SELECT origin, is_deleted 
+		SELECT origin, is_deleted, expires_at 
 		FROM events 
 		WHERE short = $1;`
+	deleteExpiredLinks = `
+		DELETE FROM events 
+		WHERE expires_at < $1;`
+	deleteSoftDeletedLinks = `
+		DELETE FROM events 
+		WHERE is_deleted AND deleted_at < $1;`
 	getOrigin = `
 		SELECT short 
 		FROM events 
 		WHERE origin = $1;`
+	getOwnLinkForUpdate = `
+		SELECT origin 
+		FROM events 
+		WHERE short = $1 AND user_id = $2 AND is_deleted IS NOT TRUE 
+		FOR UPDATE;`
+	updateLinkOrigin = `
+		UPDATE events SET origin = $2 WHERE short = $1;`
+	addLinkHistory = `
+		INSERT INTO link_history (short, origin, previous_origin, user_id) 
+		VALUES ($1, $2, $3, $4);`
+	// Clicks
+	addClicks = `
+		INSERT INTO clicks (short, clicked_at, referrer, user_agent, ip_hash)
+		SELECT * FROM unnest($1::text[], $2::timestamptz[], $3::text[], $4::text[], $5::text[]);`
+	getLinkOwner = `
+		SELECT 1 FROM events WHERE short = $1 AND user_id = $2;`
+	getDailyClicks = `
+		SELECT date_trunc('day', clicked_at AT TIME ZONE 'UTC') AS day, count(*) 
+		FROM clicks 
+		WHERE short = $1 
+		GROUP BY day 
+		ORDER BY day;`
 	// Users
 	addUser = `
 		INSERT INTO users DEFAULT VALUES RETURNING id;`
+	// getLinksByUser is formatted with the sort column, the clicks expression, the keyset comparison operator
+	// and the sort direction; the cursor condition applies only when the cursor short link ($5) is set.
 	getLinksByUser = `
-		SELECT short, origin FROM events WHERE user_id = $1;`
-	deleteLinksByUser = `
-		UPDATE events SET is_deleted = true WHERE short = $1 AND user_id = $2;`
+		SELECT short, origin, created_at, clicks FROM (
+			SELECT e.short, e.origin, e.created_at, %[2]s AS clicks
+			FROM events e
+			WHERE e.user_id = $1 AND COALESCE(e.is_deleted, false) = $2
+				AND strpos(lower(e.origin), lower($3)) > 0
+				AND ($4::timestamptz IS NULL OR e.created_at > $4)
+		) links
+		WHERE $5::text IS NULL OR (%[1]s, short) %[3]s ($6, $5)
+		ORDER BY %[1]s %[4]s, short %[4]s
+		LIMIT $7;`
+	exportLinksByUser = `
+		SELECT e.short, e.origin, e.created_at, COALESCE(e.is_deleted, false), 
+			(SELECT count(*) FROM clicks c WHERE c.short = e.short) 
+		FROM events e 
+		WHERE e.user_id = $1 
+		ORDER BY e.created_at, e.short;`
+	countLinkClicks    = `(SELECT count(*) FROM clicks c WHERE c.short = e.short)`
+	deleteLinksByUsers = `
+		UPDATE events SET is_deleted = true, deleted_at = now() 
+		FROM unnest($1::text[], $2::bigint[]) AS t(short, user_id) 
+		WHERE events.short = t.short AND events.user_id = t.user_id AND events.is_deleted IS NOT TRUE;`
+	restoreLinksByUser = `
+		UPDATE events SET is_deleted = false, deleted_at = NULL 
+		WHERE short = ANY($1::text[]) AND user_id = $2 AND is_deleted;`
+	// API keys
+	addAPIKey = `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6);`
+	getAPIKeysByUser = `
+		SELECT id, name, prefix, key_hash, created_at 
+		FROM api_keys 
+		WHERE user_id = $1 
+		ORDER BY created_at;`
+	deleteAPIKey = `
+		DELETE FROM api_keys WHERE id = $1 AND user_id = $2;`
+	getUserByAPIKey = `
+		SELECT user_id FROM api_keys WHERE key_hash = $1;`
+	// Sessions
+	addRefreshToken = `
+		INSERT INTO refresh_tokens (token_hash, user_id, expires_at) 
+		VALUES ($1, $2, $3);`
+	extendRefreshToken = `
+		UPDATE refresh_tokens SET expires_at = $2 
+		WHERE token_hash = $1 AND expires_at > now() 
+		RETURNING user_id;`
+	deleteRefreshToken = `
+		DELETE FROM refresh_tokens WHERE token_hash = $1;`
+	// Accounts
+	getUser = `
+		SELECT id, COALESCE(username, ''), COALESCE(password_hash, '') FROM users WHERE id = $1;`
+	getUserByUsername = `
+		SELECT id, username, password_hash FROM users WHERE username = $1;`
+	setCredentials = `
+		UPDATE users SET username = $2, password_hash = $3 
+		WHERE id = $1 AND username IS NULL;`
+	claimLinks = `
+		UPDATE events SET user_id = $2 WHERE user_id = $1;`
+	// Rate limits
+	refilledTokens = `
+		LEAST($2::double precision, b.tokens + 
+			GREATEST(EXTRACT(EPOCH FROM ($4::timestamptz - b.updated_at))::double precision, 0) * $3::double precision)`
+	takeRateLimitToken = `
+		INSERT INTO rate_limits AS b (key, tokens, allowed, updated_at) 
+		VALUES ($1, $2::double precision - 1, true, $4) 
+		ON CONFLICT (key) DO UPDATE SET 
+			tokens = CASE WHEN ` + refilledTokens + ` >= 1 THEN ` + refilledTokens + ` - 1 ELSE ` + refilledTokens + ` END, 
+			allowed = ` + refilledTokens + ` >= 1, 
+			updated_at = GREATEST(b.updated_at, $4) 
+		RETURNING tokens, allowed;`
+	deleteIdleRateLimits = `
+		DELETE FROM rate_limits WHERE updated_at < $1;`
 )
@@ -2,7 +2,11 @@ package psql
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"main/internal/constants"
 	"main/internal/models"
@@ -10,7 +14,7 @@ import (
 	"time"
 )
 
-// UsersRepository manages user login, link retrieval, and deletion operations in a PostgreSQL database.
+// UsersRepository manages user login, API keys, link retrieval, and deletion operations in a PostgreSQL database.
 type UsersRepository struct {
 	db *PostgresDB // Reference to the PostgreSQL database handler.
 }
@@ -33,15 +37,40 @@ func (r *UsersRepository) Login(ctx context.Context) (int64, error) {
 	return userID, nil
 }
 
-// GetLinks fetches all links created by a specific user.
-func (r *UsersRepository) GetLinks(ctx context.Context) ([]models.UserLinks, error) {
+// GetLinks fetches the links created by a specific user that match the query, sorted and paginated with a keyset.
+func (r *UsersRepository) GetLinks(ctx context.Context, query models.LinksQuery) ([]models.UserLinks, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var links []models.UserLinks
 	userID := ctx.Value(constants.UserIDKey).(int64)
 
-	rows, err := r.db.Connection.QueryContext(ctx, getLinksByUser, userID)
+	column, clicks := "created_at", "0::bigint"
+	if query.SortBy == constants.LinksSortClicks {
+		column, clicks = "clicks", countLinkClicks
+	}
+	operator, direction := ">", "ASC"
+	if query.Descending {
+		operator, direction = "<", "DESC"
+	}
+	statement := fmt.Sprintf(getLinksByUser, column, clicks, operator, direction)
+
+	var createdAfter, afterShort, afterKey, limit any
+	if !query.CreatedAfter.IsZero() {
+		createdAfter = query.CreatedAfter
+	}
+	if query.After != nil {
+		afterShort, afterKey = query.After.Short, query.After.CreatedAt
+		if query.SortBy == constants.LinksSortClicks {
+			afterKey = query.After.Clicks
+		}
+	}
+	if query.Limit > 0 {
+		limit = query.Limit
+	}
+
+	rows, err := r.db.Connection.QueryContext(ctx, statement,
+		userID, query.Deleted, query.Origin, createdAfter, afterShort, afterKey, limit)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't get the user's links: %w", err)
 	}
@@ -49,7 +78,7 @@ func (r *UsersRepository) GetLinks(ctx context.Context) ([]models.UserLinks, err
 
 	for rows.Next() {
 		var link models.UserLinks
-		err := rows.Scan(&link.Shorten, &link.Original)
+		err := rows.Scan(&link.Shorten, &link.Original, &link.CreatedAt, &link.Clicks)
 		if err != nil {
 			return nil, err
 		}
@@ -64,26 +93,200 @@ func (r *UsersRepository) GetLinks(ctx context.Context) ([]models.UserLinks, err
 	return links, nil
 }
 
-// DeleteLinks removes a list of short links belonging to a specific user.
-func (r *UsersRepository) DeleteLinks(ctx context.Context, shortLinks []string) error {
+// ExportLinks passes all links created by a specific user, including soft-deleted ones, to yield row by row
+// in creation order without loading them all into memory. It stops at the first error returned by yield.
+func (r *UsersRepository) ExportLinks(ctx context.Context, yield func(models.ExportedLink) error) error {
 	userID := ctx.Value(constants.UserIDKey).(int64)
 
-	tx, err := r.db.Connection.BeginTx(ctx, nil)
+	rows, err := r.db.Connection.QueryContext(ctx, exportLinksByUser, userID)
 	if err != nil {
-		return fmt.Errorf("couldn't start a transaction: %w", err)
+		return fmt.Errorf("couldn't export the user's links: %w", err)
 	}
+	defer rows.Close()
 
-	for _, link := range shortLinks {
-		_, err := tx.ExecContext(ctx, deleteLinksByUser, link, userID)
-		if err != nil {
-			tx.Rollback()
+	for rows.Next() {
+		var link models.ExportedLink
+		if err := rows.Scan(&link.Short, &link.Original, &link.CreatedAt, &link.Deleted, &link.Clicks); err != nil {
+			return err
+		}
+		if err := yield(link); err != nil {
 			return err
 		}
 	}
+	return rows.Err()
+}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("couldn't confirm the transaction: %w", err)
+// DeleteLinks soft-deletes a batch of links requested by possibly different users with a single statement.
+// Links that do not exist, belong to users other than the requester or are already deleted are skipped.
+func (r *UsersRepository) DeleteLinks(ctx context.Context, deletions []models.LinkDeletion) error {
+	shorts := make([]string, len(deletions))
+	userIDs := make([]int64, len(deletions))
+	for i, deletion := range deletions {
+		shorts[i], userIDs[i] = deletion.Short, deletion.UserID
 	}
 
+	if _, err := r.db.Connection.ExecContext(ctx, deleteLinksByUsers, shorts, userIDs); err != nil {
+		return fmt.Errorf("couldn't delete links: %w", err)
+	}
 	return nil
 }
+
+// RestoreLinks undoes the soft-deletion of links belonging to a specific user and returns how many were restored.
+func (r *UsersRepository) RestoreLinks(ctx context.Context, shortLinks []string) (int64, error) {
+	userID := ctx.Value(constants.UserIDKey).(int64)
+
+	res, err := r.db.Connection.ExecContext(ctx, restoreLinksByUser, shortLinks, userID)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't restore links: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// AddAPIKey stores a new API key owned by the current user.
+func (r *UsersRepository) AddAPIKey(ctx context.Context, key models.APIKey) error {
+	userID := ctx.Value(constants.UserIDKey).(int64)
+
+	_, err := r.db.Connection.ExecContext(ctx, addAPIKey, key.ID, userID, key.Name, key.Prefix, key.Hash, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("couldn't add API key: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKeys fetches the API keys owned by the current user, oldest first.
+func (r *UsersRepository) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	userID := ctx.Value(constants.UserIDKey).(int64)
+
+	rows, err := r.db.Connection.QueryContext(ctx, getAPIKeysByUser, userID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the user's API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key := models.APIKey{UserID: userID}
+		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &key.CreatedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// DeleteAPIKey revokes an API key owned by the current user.
+// It returns services.ErrAPIKeyNotFound if the user has no key with the given ID.
+func (r *UsersRepository) DeleteAPIKey(ctx context.Context, id string) error {
+	userID := ctx.Value(constants.UserIDKey).(int64)
+
+	res, err := r.db.Connection.ExecContext(ctx, deleteAPIKey, id, userID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete API key: %w", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return services.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// GetUserByAPIKey resolves the owner of an API key by the hash of the key.
+func (r *UsersRepository) GetUserByAPIKey(ctx context.Context, hash string) (int64, error) {
+	var userID int64
+
+	err := r.db.Connection.QueryRowContext(ctx, getUserByAPIKey, hash).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, services.ErrAPIKeyNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// AddRefreshToken stores a new refresh token.
+func (r *UsersRepository) AddRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	_, err := r.db.Connection.ExecContext(ctx, addRefreshToken, token.Hash, token.UserID, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("couldn't add refresh token: %w", err)
+	}
+	return nil
+}
+
+// ExtendRefreshToken moves the expiry of an unexpired refresh token and returns its owner.
+// It returns services.ErrSessionNotFound if the token is unknown or expired.
+func (r *UsersRepository) ExtendRefreshToken(ctx context.Context, hash string, expiresAt time.Time) (int64, error) {
+	var userID int64
+
+	err := r.db.Connection.QueryRowContext(ctx, extendRefreshToken, hash, expiresAt).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, services.ErrSessionNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// DeleteRefreshToken revokes a refresh token. Unknown tokens are ignored.
+func (r *UsersRepository) DeleteRefreshToken(ctx context.Context, hash string) error {
+	_, err := r.db.Connection.ExecContext(ctx, deleteRefreshToken, hash)
+	if err != nil {
+		return fmt.Errorf("couldn't delete refresh token: %w", err)
+	}
+	return nil
+}
+
+// GetUser retrieves a user by ID.
+// It returns services.ErrUserNotFound if there is no such user.
+func (r *UsersRepository) GetUser(ctx context.Context, userID int64) (models.User, error) {
+	return r.scanUser(r.db.Connection.QueryRowContext(ctx, getUser, userID))
+}
+
+// GetUserByUsername retrieves a registered user by username.
+// It returns services.ErrUserNotFound if there is no such user.
+func (r *UsersRepository) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	return r.scanUser(r.db.Connection.QueryRowContext(ctx, getUserByUsername, username))
+}
+
+// SetCredentials assigns a username and password hash to an anonymous user.
+// It returns services.ErrUsernameTaken if the username belongs to another user
+// and services.ErrUserNotFound if the user does not exist or is already registered.
+func (r *UsersRepository) SetCredentials(ctx context.Context, userID int64, username, passwordHash string) error {
+	res, err := r.db.Connection.ExecContext(ctx, setCredentials, userID, username, passwordHash)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return services.ErrUsernameTaken
+	} else if err != nil {
+		return fmt.Errorf("couldn't set credentials: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return services.ErrUserNotFound
+	}
+	return nil
+}
+
+// ClaimLinks moves all links of one user to another and returns the number of links moved.
+func (r *UsersRepository) ClaimLinks(ctx context.Context, fromUserID, toUserID int64) (int64, error) {
+	res, err := r.db.Connection.ExecContext(ctx, claimLinks, fromUserID, toUserID)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't claim links: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// scanUser reads a user from a single-row query result.
+func (r *UsersRepository) scanUser(row *sql.Row) (models.User, error) {
+	var user models.User
+
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, services.ErrUserNotFound
+	} else if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
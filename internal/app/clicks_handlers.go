@@ -0,0 +1,71 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"main/internal/constants"
+	"main/internal/interfaces"
+	"main/internal/models"
+	"main/internal/services"
+	"net/http"
+)
+
+// NewClicksHandlers constructs a new ClicksHandlers instance injected with a ClicksService.
+func NewClicksHandlers(s interfaces.ClicksService) *ClicksHandlers {
+	return &ClicksHandlers{
+		clicksService: s,
+	}
+}
+
+// ClicksHandlers encapsulates handlers exposing click analytics of user links.
+type ClicksHandlers struct {
+	clicksService interfaces.ClicksService // Dependency injection of the clicks service.
+}
+
+// GetStats handles GET requests for click statistics of a link owned by the current user.
+//
+// Possible HTTP statuses:
+//   - 200 OK: Successfully fetched the statistics.
+//   - 404 Not Found: The link does not exist or belongs to another user.
+//   - 405 Method Not Allowed: Request method is not allowed (only GET supported).
+//   - 500 Internal Server Error: An internal error occurred during statistics retrieval.
+func (h *ClicksHandlers) GetStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := h.clicksService.GetStats(ctx, r.PathValue("id"), r.Host)
+	if err != nil {
+		if errors.Is(err, services.ErrLinkNotFound) {
+			http.Error(w, "Link not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	response := models.LinkStatsResponse{
+		Shorten: stats.Short,
+		Total:   stats.Total,
+		Daily:   []models.DailyClicksResponse{},
+	}
+	for _, daily := range stats.Daily {
+		response.Daily = append(response.Daily, models.DailyClicksResponse{
+			Date:   daily.Day.Format("2006-01-02"),
+			Clicks: daily.Clicks,
+		})
+	}
+
+	resp, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", constants.JSONContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(resp)
+}
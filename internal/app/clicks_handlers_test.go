@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"main/internal/adapters"
+	"main/internal/constants"
+	"main/internal/models"
+	"main/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStats(t *testing.T) {
+	type want struct {
+		contentType string
+		statusCode  int
+		stats       *models.LinkStatsResponse
+	}
+	tests := []struct {
+		name   string
+		method string
+		short  string
+		userID int64
+		want   want
+	}{
+		{
+			name:   "positive case",
+			method: http.MethodGet,
+			short:  "stats",
+			userID: 1,
+			want: want{
+				contentType: constants.JSONContentType,
+				statusCode:  http.StatusOK,
+				stats: &models.LinkStatsResponse{
+					Shorten: "http://example.com/stats/",
+					Total:   3,
+					Daily:   []models.DailyClicksResponse{{Date: "2024-01-02", Clicks: 2}, {Date: "2024-01-03", Clicks: 1}},
+				},
+			},
+		},
+		{
+			name:   "link without clicks",
+			method: http.MethodGet,
+			short:  "quiet",
+			userID: 1,
+			want: want{
+				contentType: constants.JSONContentType,
+				statusCode:  http.StatusOK,
+				stats:       &models.LinkStatsResponse{Shorten: "http://example.com/quiet/", Daily: []models.DailyClicksResponse{}},
+			},
+		},
+		{
+			name:   "link of another user",
+			method: http.MethodGet,
+			short:  "stats",
+			userID: 2,
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusNotFound,
+			},
+		},
+		{
+			name:   "wrong method",
+			method: http.MethodPost,
+			short:  "stats",
+			userID: 1,
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusMethodNotAllowed,
+			},
+		},
+	}
+	logger := adapters.GetLogger()
+	defer adapters.SyncLogger()
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestConfig(t)
+			r, err := NewRepository(c, logger)
+			require.NoError(t, err)
+			clicks, _ := services.NewClicksService(r.clicks, "")
+			h := NewClicksHandlers(clicks)
+
+			ownerCtx := context.WithValue(context.Background(), constants.UserIDKey, int64(1))
+			for _, short := range []string{"stats", "quiet"} {
+				_, err = r.links.Add(ownerCtx, models.AddedLink{Short: short, Origin: "https://test.com/" + short})
+				require.NoError(t, err)
+			}
+			require.NoError(t, r.clicks.AddBatch(ownerCtx, []models.Click{
+				{Short: "stats", ClickedAt: day.Add(time.Hour)},
+				{Short: "stats", ClickedAt: day.Add(2 * time.Hour)},
+				{Short: "stats", ClickedAt: day.Add(25 * time.Hour)},
+			}))
+
+			request := httptest.NewRequest(test.method, "/api/user/urls/"+test.short+"/stats", nil)
+			request.SetPathValue("id", test.short)
+			request = request.WithContext(context.WithValue(request.Context(), constants.UserIDKey, test.userID))
+			w := httptest.NewRecorder()
+			h.GetStats(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.statusCode, res.StatusCode)
+			assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"))
+			if test.want.stats != nil {
+				var stats models.LinkStatsResponse
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&stats))
+				assert.Equal(t, *test.want.stats, stats)
+			}
+		})
+	}
+}
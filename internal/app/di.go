@@ -7,6 +7,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 	"log"
+	"main/internal/adapters/database/cache"
 	"main/internal/adapters/database/memory"
 	"main/internal/adapters/database/psql"
 	"main/internal/cert"
@@ -19,6 +20,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 )
 
 // Handlers organizes HTTP handlers into a coherent structure.
@@ -26,6 +28,7 @@ type Handlers struct {
 	links  interfaces.LinkHandlers   // Handler for link-related operations.
 	health interfaces.HealthHandlers // Handler for health check endpoints.
 	users  interfaces.UsersHandlers  // Handler for user-specific operations.
+	clicks interfaces.ClicksHandlers // Handler for click analytics.
 }
 
 // App encapsulates the core application state and dependencies.
@@ -37,6 +40,7 @@ type App struct {
 	cancel   context.CancelFunc // Function to cancel the application context.
 	ctx      context.Context    // Application context for signal propagation.
 	wg       sync.WaitGroup     // Wait group for tracking background tasks.
+	stopped  chan struct{}      // Closed once the HTTP server has stopped handling requests.
 }
 
 // NewApp constructs a fully-configured application instance.
@@ -45,8 +49,16 @@ func NewApp(c *config.Config, l *zap.SugaredLogger) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := middleware.ValidateUserPolicy(c.AuthPolicy); err != nil {
+		return nil, err
+	}
+	for _, limit := range []string{c.CreateRateLimit, c.RedirectRateLimit} {
+		if _, err := middleware.ParseRateLimit(limit); err != nil {
+			return nil, err
+		}
+	}
 	h := NewHandlers(s)
-	r := NewRouters(h)
+	r := NewRouters(h, c)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -57,6 +69,7 @@ func NewApp(c *config.Config, l *zap.SugaredLogger) (*App, error) {
 		Services: s,
 		ctx:      ctx,
 		cancel:   cancel,
+		stopped:  make(chan struct{}),
 	}
 	return app, nil
 }
@@ -108,9 +121,16 @@ func getTLSFiles(exeDir string) (*TLSFiles, error) {
 
 // StartServer boots the primary HTTP server and handles graceful shutdowns.
 func (a *App) StartServer() error {
-	a.wg.Add(1)
+	defer close(a.stopped)
+
+	a.wg.Add(6)
 
 	go a.startPPROFServer()
+	go a.startLinksSweeper()
+	go a.startClicksRecorder()
+	go a.startLinksDeleter()
+	go a.startDomainPolicyWatcher()
+	go a.startRateLimitsSweeper()
 
 	a.log.Infow("Starting server", "addr", a.conf.Addr)
 	a.log.Info("HTTPS status: ", a.conf.HTTPSEnable)
@@ -185,6 +205,108 @@ func (a *App) startPPROFServer() {
 	}
 }
 
+// startLinksSweeper periodically purges links whose retention after expiry or soft-deletion has passed.
+func (a *App) startLinksSweeper() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(constants.LinksSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := a.Services.links.PurgeExpired(a.ctx)
+			if err != nil {
+				a.log.Infow("Error while purging expired links", "error", err.Error())
+			} else if deleted > 0 {
+				a.log.Infow("Purged expired links", "count", deleted)
+			}
+
+			purged, err := a.Services.links.PurgeDeleted(a.ctx)
+			if err != nil {
+				a.log.Infow("Error while purging deleted links", "error", err.Error())
+			} else if purged > 0 {
+				a.log.Infow("Purged deleted links", "count", purged)
+			}
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
+
+// startDomainPolicyWatcher periodically reloads the domain policy file, so changed rules apply without a restart.
+// If the file turns out to be invalid, the previous rules stay in effect.
+func (a *App) startDomainPolicyWatcher() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(constants.DomainPolicyReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			reloaded, err := a.Services.domains.Reload()
+			if err != nil {
+				a.log.Infow("Error while reloading domain policy", "error", err.Error())
+			} else if reloaded {
+				a.log.Infow("Reloaded domain policy", "file", a.conf.DomainPolicyFile)
+			}
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
+
+// startRateLimitsSweeper periodically removes rate limit buckets that have been idle long enough to be full again,
+// which is the state a missing bucket starts in.
+func (a *App) startRateLimitsSweeper() {
+	defer a.wg.Done()
+
+	var idle time.Duration
+	for _, spec := range []string{a.conf.CreateRateLimit, a.conf.RedirectRateLimit} {
+		limit, _ := middleware.ParseRateLimit(spec)
+		idle = max(idle, limit.Period)
+	}
+
+	ticker := time.NewTicker(constants.RateLimitsSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := a.Services.Repository.rateLimits.DeleteIdle(a.ctx, time.Now().Add(-idle))
+			if err != nil {
+				a.log.Infow("Error while removing idle rate limits", "error", err.Error())
+			} else if deleted > 0 {
+				a.log.Infow("Removed idle rate limits", "count", deleted)
+			}
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
+
+// startClicksRecorder stores recorded clicks in the background until the application is closed.
+func (a *App) startClicksRecorder() {
+	defer a.wg.Done()
+
+	a.Services.clicks.Run(a.ctx)
+}
+
+// startLinksDeleter applies queued link deletions in the background until the HTTP server has stopped,
+// so that deletions accepted by requests in flight during the shutdown are still applied before the storage is closed.
+func (a *App) startLinksDeleter() {
+	defer a.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-a.stopped
+		cancel()
+	}()
+	a.Services.deleter.Run(ctx)
+}
+
 // Close gracefully cleans up running services and dependencies.
 func (a *App) Close() error {
 	a.cancel()
@@ -201,6 +323,9 @@ type Services struct {
 	links      interfaces.LinksService  // Service for link-related operations.
 	health     interfaces.HealthService // Service for health-related operations.
 	users      interfaces.UsersService  // Service for user-specific operations.
+	clicks     interfaces.ClicksService // Service for click analytics.
+	deleter    interfaces.LinksDeleter  // Background queue of link deletions.
+	domains    interfaces.DomainPolicy  // Policy deciding which domains links may point to.
 	Repository *Repository              // Encapsulation of repository access.
 }
 
@@ -215,10 +340,12 @@ func (s *Services) Close() error {
 
 // Repository abstracts the interaction with the underlying data store.
 type Repository struct {
-	links    interfaces.LinksRepository  // Repository for link operations.
-	users    interfaces.UsersRepository  // Repository for user operations.
-	health   interfaces.HealthRepository // Repository for health checks.
-	Database interfaces.DB               // Low-level database connection.
+	links      interfaces.LinksRepository     // Repository for link operations.
+	users      interfaces.UsersRepository     // Repository for user operations.
+	clicks     interfaces.ClicksRepository    // Repository for click events.
+	rateLimits interfaces.RateLimitRepository // Repository for rate limit buckets.
+	health     interfaces.HealthRepository    // Repository for health checks.
+	Database   interfaces.DB                  // Low-level database connection.
 }
 
 // Close terminates the underlying database connection.
@@ -233,9 +360,10 @@ func (s *Repository) Close() error {
 // NewHandlers builds a set of HTTP handlers from the provided services.
 func NewHandlers(s *Services) *Handlers {
 	return &Handlers{
-		links:  NewLinksHandlers(s.links),
+		links:  NewLinksHandlers(s.links, s.clicks),
 		health: NewHealthHandlers(s.health),
 		users:  NewUsersHandlers(s.users),
+		clicks: NewClicksHandlers(s.clicks),
 	}
 }
 
@@ -245,20 +373,56 @@ func NewServices(c *config.Config, l *zap.SugaredLogger) (*Services, error) {
 	if err != nil {
 		return nil, err
 	}
+	deleter := services.NewLinksDeleter(repository.users)
 	if repository.users != nil {
-		middleware.UserService = services.NewUserService(repository.users)
+		middleware.UserService = services.NewUserService(repository.users, deleter)
 	} else {
 		middleware.UserService = nil
 	}
+	if c.JWTSecret == "" && c.JWTKeysFile == "" {
+		l.Infow("JWT signing key is not configured, using an ephemeral key; access tokens will not survive a restart")
+	}
+	middleware.Keys, err = middleware.NewKeySet(c.JWTSecret, c.JWTKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	middleware.RateLimits = repository.rateLimits
+	middleware.NewUsersRateLimit, err = middleware.ParseRateLimit(c.CreateRateLimit)
+	if err != nil {
+		return nil, err
+	}
+	middleware.TrustedProxies, err = middleware.ParseTrustedProxies(c.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	domains, err := services.NewDomainPolicy(c.DomainPolicyFile)
+	if err != nil {
+		return nil, err
+	}
+	links, err := services.NewLinksService(c, repository.links, domains)
+	if err != nil {
+		return nil, err
+	}
+	if c.IPHashSecret == "" {
+		l.Infow("IP hash secret is not configured, using an ephemeral secret; click IP hashes will not match across restarts")
+	}
+	clicks, err := services.NewClicksService(repository.clicks, c.IPHashSecret)
+	if err != nil {
+		return nil, err
+	}
 	return &Services{
-		links:      services.NewLinksService(c, repository.links),
+		links:      links,
 		health:     services.NewHealthService(repository.health),
-		users:      services.NewUserService(repository.users),
+		users:      services.NewUserService(repository.users, deleter),
+		clicks:     clicks,
+		deleter:    deleter,
+		domains:    domains,
 		Repository: repository,
 	}, nil
 }
 
 // NewRepository selects and initializes the appropriate repository based on configuration.
+// Redirect lookups in a PostgreSQL database go through a cache unless its size is configured as negative.
 func NewRepository(c *config.Config, logger *zap.SugaredLogger) (*Repository, error) {
 	var repository *Repository
 
@@ -276,6 +440,11 @@ func NewRepository(c *config.Config, logger *zap.SugaredLogger) (*Repository, er
 		}
 		logger.Info("Create PostgresDB Connection")
 		repository = NewPostgresRepository(db)
+		if c.LinksCacheSize > 0 {
+			links := cache.NewLinksRepository(repository.links, c.LinksCacheSize, c.LinksCacheTTL)
+			repository.links = links
+			repository.users = cache.NewUsersRepository(repository.users, links)
+		}
 	}
 	return repository, nil
 }
@@ -283,19 +452,23 @@ func NewRepository(c *config.Config, logger *zap.SugaredLogger) (*Repository, er
 // NewInMemoryRepository constructs a repository using an in-memory database.
 func NewInMemoryRepository(db *memory.InMemoryDB) *Repository {
 	return &Repository{
-		links:    memory.NewLinksRepository(db),
-		users:    nil,
-		health:   memory.NewHealthRepository(db),
-		Database: db,
+		links:      memory.NewLinksRepository(db),
+		users:      memory.NewUsersRepository(db),
+		clicks:     memory.NewClicksRepository(db),
+		rateLimits: memory.NewRateLimitRepository(db),
+		health:     memory.NewHealthRepository(db),
+		Database:   db,
 	}
 }
 
 // NewPostgresRepository constructs a repository using a PostgreSQL database.
 func NewPostgresRepository(db *psql.PostgresDB) *Repository {
 	return &Repository{
-		links:    psql.NewLinksRepository(db),
-		users:    psql.NewUsersRepository(db),
-		health:   psql.NewHealthRepository(db),
-		Database: db,
+		links:      psql.NewLinksRepository(db),
+		users:      psql.NewUsersRepository(db),
+		clicks:     psql.NewClicksRepository(db),
+		rateLimits: psql.NewRateLimitRepository(db),
+		health:     psql.NewHealthRepository(db),
+		Database:   db,
 	}
 }
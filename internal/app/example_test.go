@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/golang/mock/gomock"
+	"main/internal/constants"
 	"main/internal/mocks"
 	"main/internal/models"
 	"net/http"
@@ -61,7 +62,7 @@ func ExampleLinksHandlers_AddLink() {
 	mockLinksService.EXPECT().Add(gomock.Any(), linkToCreate, gomock.Any()).Return(resultURL, nil)
 
 	// Create a new LinksHandlers instance using the mock service.
-	handlers := NewLinksHandlers(mockLinksService)
+	handlers := NewLinksHandlers(mockLinksService, mocks.NewMockClicksService(ctrl))
 
 	// Create a simple HTTP request.
 	reqBody := `{"url": "https://example.com"}`
@@ -107,6 +108,7 @@ func ExampleLinksHandlers_AddLinks() {
 		url := fmt.Sprintf("http://localhost/link%d", i)
 		result := models.Result{
 			Result: url,
+			Status: constants.LinkCreated,
 		}
 		results = append(results, result)
 		i++
@@ -115,7 +117,7 @@ func ExampleLinksHandlers_AddLinks() {
 	mockLinksService.EXPECT().AddBatch(gomock.Any(), originLinks, gomock.Any()).Return(results, nil)
 
 	// Create a new LinksHandlers instance using the mock service.
-	handlers := NewLinksHandlers(mockLinksService)
+	handlers := NewLinksHandlers(mockLinksService, mocks.NewMockClicksService(ctrl))
 
 	// Create a simple HTTP request.
 	reqBody := `[{"original_url": "https://example1.com"},{"original_url": "https://example2.com"}]`
@@ -134,7 +136,7 @@ func ExampleLinksHandlers_AddLinks() {
 	// Output:
 	// Response Status: 201
 	// Content Type: application/json
-	// Response body: [{"short_url":"http://localhost/link1"},{"short_url":"http://localhost/link2"}]
+	// Response body: [{"short_url":"http://localhost/link1","status":"created"},{"short_url":"http://localhost/link2","status":"created"}]
 }
 
 // ExampleGetLink demonstrates how to resolve a short link to its original URL.
@@ -150,8 +152,12 @@ func ExampleLinksHandlers_GetLink() {
 	originalURL := "https://example.com"
 	mockLinksService.EXPECT().Get(gomock.Any(), id).Return(originalURL, nil)
 
-	// Create a new LinksHandlers instance using the mock service.
-	handlers := NewLinksHandlers(mockLinksService)
+	// Create a mock ClicksService expecting the redirect to be recorded.
+	mockClicksService := mocks.NewMockClicksService(ctrl)
+	mockClicksService.EXPECT().Record(id, gomock.Any(), gomock.Any(), gomock.Any())
+
+	// Create a new LinksHandlers instance using the mock services.
+	handlers := NewLinksHandlers(mockLinksService, mockClicksService)
 
 	// Create a simple HTTP request.
 	request, _ := http.NewRequest(http.MethodGet, "/"+id+"/", nil)
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"main/internal/constants"
+	"main/internal/models"
+	"strconv"
+	"time"
+)
+
+// exportWriter writes the links of an export one at a time in one of the supported formats.
+type exportWriter interface {
+	Write(link models.ExportLinkResponse) error // Writes a single link.
+	Close() error                               // Completes the document after the last link.
+}
+
+// exportFormat describes a supported export format.
+type exportFormat struct {
+	contentType string                       // Content type of the document.
+	newWriter   func(io.Writer) exportWriter // Creates a writer of the format.
+}
+
+// exportFormats maps the values of the format query parameter to the supported export formats.
+var exportFormats = map[string]exportFormat{
+	"csv":   {contentType: constants.CSVContentType, newWriter: newCSVExportWriter},
+	"json":  {contentType: constants.JSONContentType, newWriter: newJSONExportWriter},
+	"jsonl": {contentType: constants.JSONLinesContentType, newWriter: newJSONLinesExportWriter},
+}
+
+// csvExportHeader lists the columns of a CSV export; the short and original_url columns can be imported back.
+var csvExportHeader = []string{"short", "short_url", "original_url", "created_at", "deleted", "clicks"}
+
+// csvExportWriter writes an export as CSV with a header row.
+type csvExportWriter struct {
+	writer  *csv.Writer // Underlying CSV writer.
+	started bool        // Indicates whether the header has been written.
+}
+
+// newCSVExportWriter creates a CSV export writer.
+func newCSVExportWriter(w io.Writer) exportWriter {
+	return &csvExportWriter{writer: csv.NewWriter(w)}
+}
+
+// Write writes a link as a CSV record, preceded by the header for the first one.
+func (e *csvExportWriter) Write(link models.ExportLinkResponse) error {
+	if !e.started {
+		e.started = true
+		if err := e.writer.Write(csvExportHeader); err != nil {
+			return err
+		}
+	}
+	return e.writer.Write([]string{
+		link.Short,
+		link.ShortURL,
+		link.Original,
+		link.CreatedAt.Format(time.RFC3339),
+		strconv.FormatBool(link.Deleted),
+		strconv.FormatInt(link.Clicks, 10),
+	})
+}
+
+// Close writes the header if there were no links and flushes the buffered records.
+func (e *csvExportWriter) Close() error {
+	if !e.started {
+		e.started = true
+		e.writer.Write(csvExportHeader)
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// jsonExportWriter writes an export as a single JSON array, element by element.
+type jsonExportWriter struct {
+	w       io.Writer // Destination of the document.
+	started bool      // Indicates whether the opening bracket has been written.
+}
+
+// newJSONExportWriter creates a JSON export writer.
+func newJSONExportWriter(w io.Writer) exportWriter {
+	return &jsonExportWriter{w: w}
+}
+
+// Write writes a link as the next element of the array.
+func (e *jsonExportWriter) Write(link models.ExportLinkResponse) error {
+	data, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+	separator := ","
+	if !e.started {
+		e.started, separator = true, "["
+	}
+	_, err = fmt.Fprintf(e.w, "%s%s", separator, data)
+	return err
+}
+
+// Close closes the array, writing an empty one if there were no links.
+func (e *jsonExportWriter) Close() error {
+	closing := "]"
+	if !e.started {
+		closing = "[]"
+	}
+	_, err := io.WriteString(e.w, closing)
+	return err
+}
+
+// jsonLinesExportWriter writes an export as JSON Lines, one link per line.
+type jsonLinesExportWriter struct {
+	encoder *json.Encoder // Encoder writing one line per link.
+}
+
+// newJSONLinesExportWriter creates a JSON Lines export writer.
+func newJSONLinesExportWriter(w io.Writer) exportWriter {
+	return &jsonLinesExportWriter{encoder: json.NewEncoder(w)}
+}
+
+// Write writes a link as a line.
+func (e *jsonLinesExportWriter) Write(link models.ExportLinkResponse) error {
+	return e.encoder.Encode(link)
+}
+
+// Close does nothing, since every line is complete on its own.
+func (e *jsonLinesExportWriter) Close() error {
+	return nil
+}
@@ -52,6 +52,7 @@ func TestPing(t *testing.T) {
 			request := httptest.NewRequest(test.req.method, "/ping", nil)
 			w := httptest.NewRecorder()
 
+			c := newTestConfig(t)
 			r, _ := NewRepository(c, logger)
 			l := services.NewHealthService(r.health)
 			h := NewHealthHandlers(l)
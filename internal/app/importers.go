@@ -0,0 +1,220 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"main/internal/constants"
+	"main/internal/models"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// errInvalidRow marks a malformed row of an import that is reported and skipped without stopping the import.
+var errInvalidRow = errors.New("invalid row")
+
+// importReader reads the rows of a bulk link import one at a time without buffering the whole input.
+type importReader interface {
+	// Next returns the next row. Errors wrapping errInvalidRow concern only that row, io.EOF marks the end
+	// of the input and any other error means the input cannot be read any further.
+	Next() (models.ImportLinkRequest, error)
+}
+
+// newImportReader picks the reader of an import by the content type of the request: CSV or JSON Lines.
+func newImportReader(contentType string, body io.Reader) (importReader, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	switch mediaType {
+	case constants.CSVContentType:
+		return newCSVImportReader(body), nil
+	case constants.JSONLinesContentType, "application/jsonl":
+		return newJSONLinesImportReader(body), nil
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+}
+
+// csvImportReader reads import rows from CSV records.
+// If the first record names the original_url column, it is a header mapping the short, original_url
+// and expires_at columns; otherwise the columns are taken in that order.
+type csvImportReader struct {
+	reader  *csv.Reader    // Underlying CSV reader.
+	columns map[string]int // Positions of the known columns.
+	started bool           // Indicates whether the first record has been read.
+}
+
+// newCSVImportReader creates a CSV import reader over the given input.
+func newCSVImportReader(body io.Reader) *csvImportReader {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	return &csvImportReader{
+		reader:  reader,
+		columns: map[string]int{"short": 0, "original_url": 1, "expires_at": 2},
+	}
+}
+
+// Next returns the next CSV row, skipping the header.
+func (r *csvImportReader) Next() (models.ImportLinkRequest, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return models.ImportLinkRequest{}, fmt.Errorf("%w: %v", errInvalidRow, parseErr.Err)
+		}
+		return models.ImportLinkRequest{}, err
+	}
+
+	if !r.started {
+		r.started = true
+		if header := parseCSVHeader(record); header != nil {
+			r.columns = header
+			return r.Next()
+		}
+	}
+
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	row := models.ImportLinkRequest{
+		Short: field("short"),
+		URL:   field("original_url"),
+	}
+	if value := field("expires_at"); value != "" {
+		expiresAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return row, fmt.Errorf("%w: expires_at must be an RFC 3339 timestamp", errInvalidRow)
+		}
+		row.ExpiresAt = &expiresAt
+	}
+	return row, nil
+}
+
+// parseCSVHeader maps column names to positions if the record is a header, returning nil otherwise.
+func parseCSVHeader(record []string) map[string]int {
+	columns := make(map[string]int, len(record))
+	for i, name := range record {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["original_url"]; !ok {
+		return nil
+	}
+	return columns
+}
+
+// jsonLinesImportReader reads import rows from JSON objects, one per line. Blank lines are skipped.
+type jsonLinesImportReader struct {
+	scanner *bufio.Scanner // Underlying line scanner.
+}
+
+// newJSONLinesImportReader creates a JSON Lines import reader over the given input.
+func newJSONLinesImportReader(body io.Reader) *jsonLinesImportReader {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), constants.ImportMaxLineSize)
+
+	return &jsonLinesImportReader{scanner: scanner}
+}
+
+// Next returns the next JSON Lines row.
+func (r *jsonLinesImportReader) Next() (models.ImportLinkRequest, error) {
+	var row models.ImportLinkRequest
+
+	for r.scanner.Scan() {
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(line, &row); err != nil {
+			return row, fmt.Errorf("%w: %v", errInvalidRow, err)
+		}
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return row, err
+	}
+	return row, io.EOF
+}
+
+// importRow is a row of an import waiting to be stored along with its position in the input.
+type importRow struct {
+	number int               // Number of the row in the input, starting at one.
+	link   models.OriginLink // Link to import.
+	err    error             // Reason the row is invalid, nil for valid rows.
+}
+
+// importReport streams the per-row outcomes of an import to the client as JSON Lines.
+type importReport struct {
+	w       http.ResponseWriter // Response the report is written to.
+	encoder *json.Encoder       // Encoder writing one line per row.
+	lines   int                 // Number of lines written so far.
+}
+
+// newImportReport creates a report written to the given response.
+func newImportReport(w http.ResponseWriter) *importReport {
+	return &importReport{
+		w:       w,
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// empty reports whether nothing has been written yet.
+func (r *importReport) empty() bool {
+	return r.lines == 0
+}
+
+// write reports a batch of rows, taking the outcomes of the valid ones in order from the results, and flushes
+// the response so the client sees the progress.
+func (r *importReport) write(rows []importRow, results []models.Result) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if r.empty() {
+		r.w.Header().Set("content-type", constants.JSONLinesContentType)
+		r.w.WriteHeader(http.StatusOK)
+	}
+	for _, row := range rows {
+		line := models.ImportLinkResponse{Row: row.number}
+		if row.err != nil {
+			line.Status, line.Error = constants.LinkInvalid, row.err.Error()
+		} else {
+			result := results[0]
+			results = results[1:]
+			line.Status = result.Status
+			if result.Status == constants.LinkInvalid {
+				line.Error = result.Result
+			} else {
+				line.Result = result.Result
+			}
+		}
+		if err := r.encoder.Encode(line); err != nil {
+			return err
+		}
+		r.lines++
+	}
+	http.NewResponseController(r.w).Flush()
+	return nil
+}
+
+// abort ends the report with the reason the rest of the input was not imported.
+// If nothing has been reported yet, a plain error response is sent instead.
+func (r *importReport) abort(err error) {
+	if r.empty() {
+		http.Error(r.w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	r.encoder.Encode(models.ImportLinkResponse{Status: constants.ImportAborted, Error: err.Error()})
+}
@@ -4,32 +4,40 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"main/internal/constants"
 	"main/internal/interfaces"
+	"main/internal/middleware"
 	"main/internal/models"
 	"main/internal/services"
+	"math"
 	"net/http"
+	"time"
 )
 
-// NewLinksHandlers constructs a new LinksHandlers instance initialized with a LinksService.
-func NewLinksHandlers(s interfaces.LinksService) *LinksHandlers {
+// NewLinksHandlers constructs a new LinksHandlers instance initialized with a LinksService and a ClicksService.
+func NewLinksHandlers(s interfaces.LinksService, c interfaces.ClicksService) *LinksHandlers {
 	return &LinksHandlers{
-		linksService: s,
+		linksService:  s,
+		clicksService: c,
 	}
 }
 
 // LinksHandlers encapsulates handlers for managing links and redirections.
 type LinksHandlers struct {
-	linksService interfaces.LinksService // Dependency injection of the links service.
+	linksService  interfaces.LinksService  // Dependency injection of the links service.
+	clicksService interfaces.ClicksService // Dependency injection of the clicks service.
 }
 
 // GetLink handles GET requests for resolving short links to their original URLs.
+// Every successful redirect is recorded as a click.
 //
 // Possible HTTP statuses:
 //   - 200 OK: Successfully redirected to the original URL.
 //   - 404 Not Found: Original URL was not found.
-//   - 410 Gone: Original URL has been deleted.
+//   - 410 Gone: Original URL has been deleted or has expired.
+//   - 451 Unavailable For Legal Reasons: The domain of the original URL is blocked by the domain policy.
 //   - 405 Method Not Allowed: Request method is not allowed (only GET supported).
 func (h *LinksHandlers) GetLink(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -45,21 +53,28 @@ func (h *LinksHandlers) GetLink(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if errors.Is(err, services.ErrDeletedLink) {
 			http.Error(w, "Origin is deleted", http.StatusGone)
+		} else if errors.Is(err, services.ErrExpiredLink) {
+			http.Error(w, "Origin is expired", http.StatusGone)
+		} else if errors.Is(err, services.ErrBlockedDomain) {
+			http.Error(w, "Origin domain is blocked", http.StatusUnavailableForLegalReasons)
 		} else {
 			http.Error(w, "Origin not found", http.StatusNotFound)
 		}
 		return
 	}
 
+	h.clicksService.Record(shortLink, r.Referer(), r.UserAgent(), middleware.ClientIP(r))
+
 	w.Header().Set("content-type", constants.TextContentType)
 	w.Header().Set("Location", originLink)
 	w.WriteHeader(http.StatusTemporaryRedirect)
 }
 
 // AddLinks processes POST requests for batch-link creation.
+// Each item of the response carries its own status: created, exists or invalid.
 //
 // Possible HTTP statuses:
-//   - 201 Created: All links successfully created.
+//   - 201 Created: Batch processed, see the per-item statuses.
 //   - 400 Bad Request: Malformed request body.
 //   - 405 Method Not Allowed: Request method is not allowed (only POST supported).
 //   - 500 Internal Server Error: An internal error occurred during link creation.
@@ -72,7 +87,6 @@ func (h *LinksHandlers) AddLinks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var shortenRequests []models.ShortensRequest
-	var responses []models.ShortensResponse
 
 	buf := new(bytes.Buffer)
 	_, err := buf.ReadFrom(r.Body)
@@ -85,10 +99,26 @@ func (h *LinksHandlers) AddLinks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	responses := make([]models.ShortensResponse, len(shortenRequests))
+
 	var originLinks []models.OriginLink
-	for _, req := range shortenRequests {
-		originLink := models.OriginLink(req)
+	var indexes []int
+	for i, req := range shortenRequests {
+		ttl, err := ttlDuration(req.TTL)
+		if err != nil {
+			responses[i] = models.ShortensResponse{CorrelationID: req.CorrelationID, Result: err.Error(), Status: constants.LinkInvalid}
+			continue
+		}
+		originLink := models.OriginLink{
+			CorrelationID: req.CorrelationID,
+			URL:           req.URL,
+			TTL:           ttl,
+		}
+		if req.ExpiresAt != nil {
+			originLink.ExpiresAt = *req.ExpiresAt
+		}
 		originLinks = append(originLinks, originLink)
+		indexes = append(indexes, i)
 	}
 
 	results, err := h.linksService.AddBatch(ctx, originLinks, r.Host)
@@ -97,9 +127,8 @@ func (h *LinksHandlers) AddLinks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	for _, result := range results {
-		shortensResponse := models.ShortensResponse(result)
-		responses = append(responses, shortensResponse)
+	for i, result := range results {
+		responses[indexes[i]] = models.ShortensResponse(result)
 	}
 
 	resp, err := json.Marshal(responses)
@@ -117,9 +146,10 @@ func (h *LinksHandlers) AddLinks(w http.ResponseWriter, r *http.Request) {
 //
 // Possible HTTP statuses:
 //   - 201 Created: Link successfully created.
-//   - 400 Bad Request: Malformed request body.
+//   - 400 Bad Request: Malformed request body, invalid URL, alias or expiration.
+//   - 403 Forbidden: The domain of the URL is blocked by the domain policy.
 //   - 405 Method Not Allowed: Request method is not allowed (only POST supported).
-//   - 409 Conflict: Duplicate link already exists.
+//   - 409 Conflict: Duplicate link already exists or the alias is already taken.
 //   - 500 Internal Server Error: An internal error occurred during link creation.
 func (h *LinksHandlers) AddLink(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -143,8 +173,19 @@ func (h *LinksHandlers) AddLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ttl, err := ttlDuration(shortenRequest.TTL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	originLink := models.OriginLink{
-		URL: shortenRequest.URL,
+		URL:   shortenRequest.URL,
+		Alias: shortenRequest.Alias,
+		TTL:   ttl,
+	}
+	if shortenRequest.ExpiresAt != nil {
+		originLink.ExpiresAt = *shortenRequest.ExpiresAt
 	}
 
 	status := http.StatusCreated
@@ -153,6 +194,15 @@ func (h *LinksHandlers) AddLink(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if errors.Is(err, services.ErrConflict) {
 			status = http.StatusConflict
+		} else if errors.Is(err, services.ErrInvalidURL) || errors.Is(err, services.ErrInvalidAlias) || errors.Is(err, services.ErrInvalidExpiration) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if errors.Is(err, services.ErrBlockedDomain) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		} else if errors.Is(err, services.ErrShortLinkTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -170,12 +220,144 @@ func (h *LinksHandlers) AddLink(w http.ResponseWriter, r *http.Request) {
 	w.Write(resp)
 }
 
+// UpdateLink handles PATCH requests changing the destination of a link owned by the current user.
+//
+// Possible HTTP statuses:
+//   - 204 No Content: Destination changed.
+//   - 400 Bad Request: Malformed request body or invalid URL.
+//   - 403 Forbidden: The domain of the new URL is blocked by the domain policy.
+//   - 404 Not Found: The link does not exist, is deleted or belongs to another user.
+//   - 409 Conflict: The new URL has already been shortened by another link.
+//   - 500 Internal Server Error: An internal error occurred during the update.
+func (h *LinksHandlers) UpdateLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	defer r.Body.Close()
+
+	var req models.UpdateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Couldn't parse the request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.linksService.Update(ctx, r.PathValue("id"), req.URL)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidURL):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrBlockedDomain):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, services.ErrLinkNotFound):
+			http.Error(w, "Link not found", http.StatusNotFound)
+		case errors.Is(err, services.ErrConflict):
+			http.Error(w, "The URL has already been shortened", http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportLinks handles POST requests importing links migrated from another shortener.
+// The body is read as CSV or JSON Lines depending on its content type and imported in batches as it streams in,
+// preserving the provided short codes when they are free. The response is a JSON Lines report with one line
+// per row in input order; if the rest of the input cannot be imported, the report ends with an aborted line.
+//
+// Possible HTTP statuses:
+//   - 200 OK: Import processed, see the per-row statuses.
+//   - 400 Bad Request: The input contains no rows.
+//   - 405 Method Not Allowed: Request method is not allowed (only POST supported).
+//   - 415 Unsupported Media Type: The body is neither CSV nor JSON Lines.
+//   - 500 Internal Server Error: An internal error occurred before any row was imported.
+func (h *LinksHandlers) ImportLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	defer r.Body.Close()
+
+	reader, err := newImportReader(r.Header.Get("Content-Type"), r.Body)
+	if err != nil {
+		http.Error(w, "The body must be CSV or JSON Lines", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	report := newImportReport(w)
+	rows := make([]importRow, 0, constants.ImportBatchSize)
+
+	flush := func() error {
+		var originLinks []models.OriginLink
+		for _, row := range rows {
+			if row.err == nil {
+				originLinks = append(originLinks, row.link)
+			}
+		}
+		var results []models.Result
+		if len(originLinks) > 0 {
+			var err error
+			if results, err = h.linksService.Import(ctx, originLinks, r.Host); err != nil {
+				return err
+			}
+		}
+		if err := report.write(rows, results); err != nil {
+			return err
+		}
+		rows = rows[:0]
+		return nil
+	}
+
+	for number := 1; ; number++ {
+		req, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, errInvalidRow) {
+			if flushErr := flush(); flushErr != nil {
+				err = flushErr
+			}
+			report.abort(err)
+			return
+		}
+
+		row := importRow{number: number, err: err}
+		if err == nil {
+			row.link = models.OriginLink{URL: req.URL, Alias: req.Short}
+			if req.ExpiresAt != nil {
+				row.link.ExpiresAt = *req.ExpiresAt
+			}
+		}
+		rows = append(rows, row)
+
+		if len(rows) == constants.ImportBatchSize {
+			if err := flush(); err != nil {
+				report.abort(err)
+				return
+			}
+		}
+	}
+
+	if len(rows) == 0 && report.empty() {
+		http.Error(w, "The import contains no rows", http.StatusBadRequest)
+		return
+	}
+	if err := flush(); err != nil {
+		report.abort(err)
+	}
+}
+
 // AddLinkInText processes link creation directly from plain-text bodies.
+// A custom alias may be requested with the "alias" query parameter.
 //
 // Possible HTTP statuses:
 //   - 201 Created: Link successfully created.
+//   - 400 Bad Request: Invalid URL or alias.
+//   - 403 Forbidden: The domain of the URL is blocked by the domain policy.
 //   - 405 Method Not Allowed: Request method is not allowed (only POST supported).
-//   - 409 Conflict: Duplicate link already exists.
+//   - 409 Conflict: Duplicate link already exists or the alias is already taken.
 //   - 500 Internal Server Error: An internal error occurred during link creation.
 func (h *LinksHandlers) AddLinkInText(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -191,7 +373,8 @@ func (h *LinksHandlers) AddLinkInText(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	originLink := models.OriginLink{
-		URL: string(body),
+		URL:   string(body),
+		Alias: r.URL.Query().Get("alias"),
 	}
 	status := http.StatusCreated
 
@@ -199,6 +382,15 @@ func (h *LinksHandlers) AddLinkInText(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if errors.Is(err, services.ErrConflict) {
 			status = http.StatusConflict
+		} else if errors.Is(err, services.ErrInvalidURL) || errors.Is(err, services.ErrInvalidAlias) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if errors.Is(err, services.ErrBlockedDomain) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		} else if errors.Is(err, services.ErrShortLinkTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -209,3 +401,11 @@ func (h *LinksHandlers) AddLinkInText(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(status)
 	w.Write([]byte(response))
 }
+
+// ttlDuration converts a link lifetime in seconds to a duration, rejecting lifetimes that would overflow it.
+func ttlDuration(ttl int64) (time.Duration, error) {
+	if ttl > math.MaxInt64/int64(time.Second) || ttl < math.MinInt64/int64(time.Second) {
+		return 0, fmt.Errorf("%w: ttl is too large", services.ErrInvalidExpiration)
+	}
+	return time.Duration(ttl) * time.Second, nil
+}
@@ -3,7 +3,11 @@ package app
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"io"
 	"main/internal/adapters"
 	"main/internal/config"
 	"main/internal/constants"
@@ -11,15 +15,27 @@ import (
 	"main/internal/services"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-var c = &config.Config{
-	StorageFilePaths: "test.json",
+// newTestConfig returns a configuration storing links in a fresh file of the test's temporary directory.
+func newTestConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		StorageFilePaths:  filepath.Join(t.TempDir(), "test.json"),
+		ShortCodeStrategy: services.RandomStrategy,
+		ShortCodeLength:   8,
+	}
 }
 
+var domains, _ = services.NewDomainPolicy("")
+
 func TestAddLinkInText(t *testing.T) {
 	type want struct {
 		contentType string
@@ -32,6 +48,7 @@ func TestAddLinkInText(t *testing.T) {
 		name string
 		want want
 		req  req
+		body string
 	}{
 		{
 			name: "positive case",
@@ -42,6 +59,28 @@ func TestAddLinkInText(t *testing.T) {
 			req: req{
 				method: http.MethodPost,
 			},
+			body: "https://Test.com/%s",
+		},
+		{
+			name: "empty body",
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusBadRequest,
+			},
+			req: req{
+				method: http.MethodPost,
+			},
+		},
+		{
+			name: "javascript url",
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusBadRequest,
+			},
+			req: req{
+				method: http.MethodPost,
+			},
+			body: "javascript:alert(1)",
 		},
 		{
 			name: "wrong method",
@@ -58,12 +97,18 @@ func TestAddLinkInText(t *testing.T) {
 	defer adapters.SyncLogger()
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			request := httptest.NewRequest(test.req.method, "/", nil)
+			body := test.body
+			if strings.Contains(body, "%s") {
+				body = fmt.Sprintf(body, uuid.NewString())
+			}
+			request := httptest.NewRequest(test.req.method, "/", strings.NewReader(body))
 			w := httptest.NewRecorder()
 
+			c := newTestConfig(t)
 			r, _ := NewRepository(c, logger)
-			l := services.NewLinksService(c, r.links)
-			h := NewLinksHandlers(l)
+			l, _ := services.NewLinksService(c, r.links, domains)
+			clicks, _ := services.NewClicksService(r.clicks, "")
+			h := NewLinksHandlers(l, clicks)
 
 			h.AddLinkInText(w, request)
 
@@ -124,6 +169,50 @@ func TestAddLink(t *testing.T) {
 			},
 			body: ``,
 		},
+		{
+			name: "reserved alias",
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusBadRequest,
+			},
+			req: req{
+				method: http.MethodPost,
+			},
+			body: `{"url":"https://go.dev/blog/package-names","alias":"api"}`,
+		},
+		{
+			name: "invalid alias",
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusBadRequest,
+			},
+			req: req{
+				method: http.MethodPost,
+			},
+			body: `{"url":"https://go.dev/blog/package-names","alias":"spring sale!"}`,
+		},
+		{
+			name: "overflowing ttl",
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusBadRequest,
+			},
+			req: req{
+				method: http.MethodPost,
+			},
+			body: `{"url":"https://go.dev/blog/package-names","ttl":18446744074}`,
+		},
+		{
+			name: "overflowing negative ttl",
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusBadRequest,
+			},
+			req: req{
+				method: http.MethodPost,
+			},
+			body: `{"url":"https://go.dev/blog/package-names","ttl":-9223372037}`,
+		},
 	}
 	logger := adapters.GetLogger()
 	defer adapters.SyncLogger()
@@ -135,9 +224,11 @@ func TestAddLink(t *testing.T) {
 			request := httptest.NewRequest(test.req.method, "/api/shorten", &buf)
 			w := httptest.NewRecorder()
 
+			c := newTestConfig(t)
 			r, _ := NewRepository(c, logger)
-			l := services.NewLinksService(c, r.links)
-			h := NewLinksHandlers(l)
+			l, _ := services.NewLinksService(c, r.links, domains)
+			clicks, _ := services.NewClicksService(r.clicks, "")
+			h := NewLinksHandlers(l, clicks)
 
 			h.AddLink(w, request)
 
@@ -155,6 +246,7 @@ func TestAddLinks(t *testing.T) {
 	type want struct {
 		contentType string
 		statusCode  int
+		statuses    []string
 	}
 	type req struct {
 		method string
@@ -175,8 +267,38 @@ func TestAddLinks(t *testing.T) {
 				method: http.MethodPost,
 			},
 			body: `[
-						{"correlation_id": "wf","original_url": "https://go.dev/blog/package-names/1"},
-						{"correlation_id": "wf","original_url": "https://go.dev/blog/package-names"}
+						{"correlation_id": "wf","original_url": "https://go.dev/blog/errors-are-values"},
+						{"correlation_id": "wf","original_url": "https://go.dev/blog/error-handling-and-go"}
+					]`,
+		},
+		{
+			name: "duplicate origins",
+			want: want{
+				contentType: constants.JSONContentType,
+				statusCode:  http.StatusCreated,
+			},
+			req: req{
+				method: http.MethodPost,
+			},
+			body: `[
+						{"correlation_id": "1","original_url": "https://go.dev/blog/defer-panic-and-recover"},
+						{"correlation_id": "2","original_url": "https://go.dev/blog/defer-panic-and-recover"}
+					]`,
+		},
+		{
+			name: "overflowing ttl",
+			want: want{
+				contentType: constants.JSONContentType,
+				statusCode:  http.StatusCreated,
+				statuses:    []string{constants.LinkCreated, constants.LinkInvalid, constants.LinkCreated},
+			},
+			req: req{
+				method: http.MethodPost,
+			},
+			body: `[
+						{"correlation_id": "1","original_url": "https://go.dev/blog/slices-intro","ttl": 60},
+						{"correlation_id": "2","original_url": "https://go.dev/blog/maps","ttl": 18446744074},
+						{"correlation_id": "3","original_url": "https://go.dev/blog/strings"}
 					]`,
 		},
 		{
@@ -212,9 +334,11 @@ func TestAddLinks(t *testing.T) {
 			request := httptest.NewRequest(test.req.method, "/api/shorten/batch", &buf)
 			w := httptest.NewRecorder()
 
+			c := newTestConfig(t)
 			r, _ := NewRepository(c, logger)
-			l := services.NewLinksService(c, r.links)
-			h := NewLinksHandlers(l)
+			l, _ := services.NewLinksService(c, r.links, domains)
+			clicks, _ := services.NewClicksService(r.clicks, "")
+			h := NewLinksHandlers(l, clicks)
 
 			h.AddLinks(w, request)
 
@@ -222,6 +346,15 @@ func TestAddLinks(t *testing.T) {
 
 			assert.Equal(t, test.want.statusCode, res.StatusCode)
 			assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"))
+			if test.want.statuses != nil {
+				var responses []models.ShortensResponse
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&responses))
+				var statuses []string
+				for _, response := range responses {
+					statuses = append(statuses, response.Status)
+				}
+				assert.Equal(t, test.want.statuses, statuses)
+			}
 
 			res.Body.Close()
 		})
@@ -237,9 +370,11 @@ func TestGetLink(t *testing.T) {
 		method string
 	}
 	tests := []struct {
-		name string
-		want want
-		req  req
+		name      string
+		want      want
+		req       req
+		origin    string
+		expiresAt time.Time
 	}{
 		{
 			name: "positive case",
@@ -261,10 +396,52 @@ func TestGetLink(t *testing.T) {
 				method: http.MethodPost,
 			},
 		},
+		{
+			name: "expired link",
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusGone,
+			},
+			req: req{
+				method: http.MethodGet,
+			},
+			expiresAt: time.Now().Add(-time.Minute),
+		},
+		{
+			name: "blocked domain",
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusUnavailableForLegalReasons,
+			},
+			req: req{
+				method: http.MethodGet,
+			},
+			origin: "https://ads.blocked.example/",
+		},
+		{
+			name: "blocked domain without scheme",
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusUnavailableForLegalReasons,
+			},
+			req: req{
+				method: http.MethodGet,
+			},
+			origin: "ads.blocked.example/",
+		},
 	}
 	logger := adapters.GetLogger()
 	defer adapters.SyncLogger()
 	ctx := context.Background()
+
+	policyFile := filepath.Join(t.TempDir(), "domains.json")
+	if err := os.WriteFile(policyFile, []byte(`{"blocked": [".blocked.example"]}`), 0o600); err != nil {
+		t.Fatalf("Failed to write domain policy")
+	}
+	policy, err := services.NewDomainPolicy(policyFile)
+	if err != nil {
+		t.Fatalf("Failed to load domain policy: %v", err)
+	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 
@@ -274,13 +451,19 @@ func TestGetLink(t *testing.T) {
 				return
 			}
 
+			c := newTestConfig(t)
 			r, _ := NewRepository(c, logger)
-			l := services.NewLinksService(c, r.links)
-			h := NewLinksHandlers(l)
+			l, _ := services.NewLinksService(c, r.links, policy)
+			clicks, _ := services.NewClicksService(r.clicks, "")
+			h := NewLinksHandlers(l, clicks)
 
 			addedLink := models.AddedLink{
-				Short:  u.String(),
-				Origin: "test.com",
+				Short:     u.String(),
+				Origin:    "test.com/" + u.String(),
+				ExpiresAt: test.expiresAt,
+			}
+			if test.origin != "" {
+				addedLink.Origin = test.origin + u.String()
 			}
 
 			id, err := r.links.Add(ctx, addedLink)
@@ -308,3 +491,212 @@ func TestGetLink(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateLink(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		userID     int64
+		wantStatus int
+	}{
+		{name: "positive case", body: `{"url":"https://test.com/new/%s"}`, userID: 1, wantStatus: http.StatusNoContent},
+		{name: "other user", body: `{"url":"https://test.com/new/%s"}`, userID: 2, wantStatus: http.StatusNotFound},
+		{name: "empty url", body: `{"url":""}`, userID: 1, wantStatus: http.StatusBadRequest},
+		{name: "relative url", body: `{"url":"/new/%s"}`, userID: 1, wantStatus: http.StatusBadRequest},
+		{name: "malformed body", body: `{"url":`, userID: 1, wantStatus: http.StatusBadRequest},
+	}
+	logger := adapters.GetLogger()
+	defer adapters.SyncLogger()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := uuid.NewRandom()
+			if err != nil {
+				t.Fatalf("Failed to generate UUID")
+				return
+			}
+
+			c := newTestConfig(t)
+			r, _ := NewRepository(c, logger)
+			l, _ := services.NewLinksService(c, r.links, domains)
+			clicks, _ := services.NewClicksService(r.clicks, "")
+			h := NewLinksHandlers(l, clicks)
+
+			ownerCtx := context.WithValue(context.Background(), constants.UserIDKey, int64(1))
+			id, err := r.links.Add(ownerCtx, models.AddedLink{Short: u.String(), Origin: "test.com/" + u.String()})
+			if err != nil {
+				t.Fatalf("Failed to add link")
+				return
+			}
+
+			body := test.body
+			if strings.Contains(body, "%s") {
+				body = fmt.Sprintf(body, u.String())
+			}
+			request := httptest.NewRequest(http.MethodPatch, "/api/user/urls/"+id, strings.NewReader(body))
+			request.SetPathValue("id", id)
+			request = request.WithContext(context.WithValue(request.Context(), constants.UserIDKey, test.userID))
+
+			w := httptest.NewRecorder()
+			h.UpdateLink(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestImportLinks(t *testing.T) {
+	tests := []struct {
+		name         string
+		contentType  string
+		body         string
+		wantStatus   int
+		wantStatuses []string
+	}{
+		{
+			name:         "csv with header",
+			contentType:  constants.CSVContentType,
+			body:         "original_url,short\nhttps://test.com/%[1]s/a,a%[2]s\nhttps://test.com/%[1]s/b,a%[2]s\nhttps://test.com/%[1]s/a,\n,c%[2]s\n",
+			wantStatus:   http.StatusOK,
+			wantStatuses: []string{constants.LinkCreated, constants.LinkRenamed, constants.LinkExists, constants.LinkInvalid},
+		},
+		{
+			name:         "json lines",
+			contentType:  constants.JSONLinesContentType,
+			body:         `{"short":"j%[2]s","original_url":"https://test.com/%[1]s/j"}` + "\n\n{\n" + `{"original_url":"https://test.com/%[1]s/k"}`,
+			wantStatus:   http.StatusOK,
+			wantStatuses: []string{constants.LinkCreated, constants.LinkInvalid, constants.LinkCreated},
+		},
+		{name: "empty", contentType: constants.CSVContentType, body: "", wantStatus: http.StatusBadRequest},
+		{name: "unsupported type", contentType: constants.JSONContentType, body: "[]", wantStatus: http.StatusUnsupportedMediaType},
+	}
+	logger := adapters.GetLogger()
+	defer adapters.SyncLogger()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := uuid.NewRandom()
+			if err != nil {
+				t.Fatalf("Failed to generate UUID")
+				return
+			}
+
+			c := newTestConfig(t)
+			r, _ := NewRepository(c, logger)
+			l, _ := services.NewLinksService(c, r.links, domains)
+			clicks, _ := services.NewClicksService(r.clicks, "")
+			h := NewLinksHandlers(l, clicks)
+
+			body := test.body
+			if strings.Contains(body, "%[") {
+				body = fmt.Sprintf(body, u.String(), strings.ReplaceAll(u.String(), "-", "")[:12])
+			}
+			request := httptest.NewRequest(http.MethodPost, "/api/user/import", strings.NewReader(body))
+			request.Header.Set("Content-Type", test.contentType)
+			request = request.WithContext(context.WithValue(request.Context(), constants.UserIDKey, int64(1)))
+
+			w := httptest.NewRecorder()
+			h.ImportLinks(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+
+			var statuses []string
+			decoder := json.NewDecoder(res.Body)
+			for decoder.More() {
+				var line models.ImportLinkResponse
+				if err := decoder.Decode(&line); err != nil {
+					break
+				}
+				statuses = append(statuses, line.Status)
+			}
+			if test.wantStatuses != nil {
+				assert.Equal(t, test.wantStatuses, statuses)
+			}
+		})
+	}
+}
+
+// failingBody returns its data and then fails, canceling the request first if cancel is set.
+type failingBody struct {
+	data   io.Reader
+	cancel context.CancelFunc
+}
+
+// Read reads the data and fails once it is exhausted.
+func (b *failingBody) Read(p []byte) (int, error) {
+	n, err := b.data.Read(p)
+	if err != io.EOF {
+		return n, err
+	}
+	if b.cancel != nil {
+		b.cancel()
+	}
+	return n, errors.New("connection reset")
+}
+
+func TestImportLinksReadFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		rows      int
+		cancel    bool
+		wantLines int
+		wantError string
+	}{
+		{name: "read failure", rows: 2, wantLines: 2, wantError: "connection reset"},
+		{name: "read and import failure", rows: constants.ImportBatchSize + 1, cancel: true, wantLines: constants.ImportBatchSize, wantError: context.Canceled.Error()},
+	}
+	logger := adapters.GetLogger()
+	defer adapters.SyncLogger()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestConfig(t)
+			r, _ := NewRepository(c, logger)
+			l, _ := services.NewLinksService(c, r.links, domains)
+			clicks, _ := services.NewClicksService(r.clicks, "")
+			h := NewLinksHandlers(l, clicks)
+
+			var csv strings.Builder
+			csv.WriteString("original_url,short\n")
+			for i := 0; i < test.rows; i++ {
+				fmt.Fprintf(&csv, "https://test.com/%d,\n", i)
+			}
+
+			ctx, cancel := context.WithCancel(context.WithValue(context.Background(), constants.UserIDKey, int64(1)))
+			defer cancel()
+			body := &failingBody{data: strings.NewReader(csv.String())}
+			if test.cancel {
+				body.cancel = cancel
+			}
+			request := httptest.NewRequest(http.MethodPost, "/api/user/import", body).WithContext(ctx)
+			request.Header.Set("Content-Type", constants.CSVContentType)
+
+			w := httptest.NewRecorder()
+			h.ImportLinks(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+
+			var lines []models.ImportLinkResponse
+			decoder := json.NewDecoder(res.Body)
+			for decoder.More() {
+				var line models.ImportLinkResponse
+				if err := decoder.Decode(&line); err != nil {
+					break
+				}
+				lines = append(lines, line)
+			}
+			require.Len(t, lines, test.wantLines+1)
+			for _, line := range lines[:test.wantLines] {
+				assert.Equal(t, constants.LinkCreated, line.Status)
+			}
+			last := lines[test.wantLines]
+			assert.Equal(t, constants.ImportAborted, last.Status, "the report must not look complete")
+			assert.Contains(t, last.Error, test.wantError)
+		})
+	}
+}
@@ -2,28 +2,67 @@ package app
 
 import (
 	"github.com/go-chi/chi/v5"
+	"main/internal/config"
+	"main/internal/constants"
 	"main/internal/middleware"
 )
 
 // NewRouters constructs and configures the main router with middleware and routes.
-func NewRouters(h *Handlers) *chi.Mux {
+// Authentication is applied per route group: redirects and health checks are public,
+// link creation follows the configured policy, sign-in routes use an identity only if present
+// and the other user routes require an existing identity.
+// Link creation routes share one rate limit budget and redirects have another, both applied after authentication
+// so that identified users are limited by their ID. Anonymous users are limited by their IP address
+// within authentication, before they are created.
+func NewRouters(h *Handlers, c *config.Config) *chi.Mux {
+	public := middleware.Authentication(middleware.PublicPolicy)
+	writer := middleware.Authentication(c.AuthPolicy)
+	user := middleware.Authentication(middleware.RequiredPolicy)
+	optional := middleware.Authentication(middleware.OptionalPolicy)
+
+	// The limits are validated by NewApp.
+	createLimit, _ := middleware.ParseRateLimit(c.CreateRateLimit)
+	redirectLimit, _ := middleware.ParseRateLimit(c.RedirectRateLimit)
+	createLimiter := middleware.RateLimit(constants.CreateRateLimitScope, createLimit)
+	redirectLimiter := middleware.RateLimit(constants.RedirectRateLimitScope, redirectLimit)
+
 	r := chi.NewRouter()
 	r.Use(middleware.AccessLogger)
 	r.Use(middleware.GZipper)
-	r.Use(middleware.Authentication)
 
 	r.Route("/", func(r chi.Router) {
-		r.Get("/ping", h.health.Ping)
-		r.Post("/", h.links.AddLinkInText)
+		r.With(public).Get("/ping", h.health.Ping)
+		r.With(writer, createLimiter).Post("/", h.links.AddLinkInText)
 		r.Route("/{id}", func(r chi.Router) {
+			r.Use(public, redirectLimiter)
 			r.Get("/", h.links.GetLink)
 		})
 		r.Route("/api", func(r chi.Router) {
 			r.Route("/user", func(r chi.Router) {
-				r.Get("/urls", h.users.GetLinks)
-				r.Delete("/urls", h.users.DeleteLinks)
+				r.Group(func(r chi.Router) {
+					r.Use(optional)
+					r.Post("/register", h.users.Register)
+					r.Post("/login", h.users.Login)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(user)
+					r.Get("/urls", h.users.GetLinks)
+					r.Get("/urls/export", h.users.ExportLinks)
+					r.Delete("/urls", h.users.DeleteLinks)
+					r.Post("/urls/restore", h.users.RestoreLinks)
+					r.Patch("/urls/{id}", h.links.UpdateLink)
+					r.Get("/urls/{id}/stats", h.clicks.GetStats)
+					r.With(createLimiter).Post("/import", h.links.ImportLinks)
+					r.Post("/logout", h.users.Logout)
+					r.Route("/keys", func(r chi.Router) {
+						r.Post("/", h.users.CreateAPIKey)
+						r.Get("/", h.users.GetAPIKeys)
+						r.Delete("/{id}", h.users.RevokeAPIKey)
+					})
+				})
 			})
 			r.Route("/shorten", func(r chi.Router) {
+				r.Use(writer, createLimiter)
 				r.Post("/", h.links.AddLink)
 				r.Post("/batch", h.links.AddLinks)
 			})
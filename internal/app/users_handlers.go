@@ -3,11 +3,17 @@ package app // Package app implements HTTP handlers for user-related operations.
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"main/internal/constants"
 	"main/internal/interfaces"
+	"main/internal/middleware"
 	"main/internal/models"
 	"main/internal/services"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // NewUsersHandlers constructs a new UsersHandlers instance injected with a UsersService.
@@ -23,10 +29,16 @@ type UsersHandlers struct {
 }
 
 // GetLinks handles GET requests for retrieving links associated with the currently-authenticated user.
+// Soft-deleted links are listed instead of the active ones with the "deleted=true" query parameter.
+// The links may be filtered by a substring of the original URL ("origin") and by creation time ("created_after",
+// RFC 3339) and sorted by "created" or "clicks" ("sort") in "asc" or "desc" order ("order").
+// Without the "limit" and "cursor" parameters all links are returned as a JSON array; otherwise a page of at most
+// "limit" links is returned along with the "next_cursor" to pass as "cursor" for the following page.
 //
 // Possible HTTP statuses:
 //   - 200 OK: Successfully fetched the user's links.
-//   - 204 No Content: The user has no links.
+//   - 204 No Content: The user has no matching links.
+//   - 400 Bad Request: A query parameter or the cursor is invalid.
 //   - 405 Method Not Allowed: Request method is not allowed (only GET supported).
 //   - 500 Internal Server Error: An internal error occurred during link retrieval.
 func (h *UsersHandlers) GetLinks(w http.ResponseWriter, r *http.Request) {
@@ -37,26 +49,46 @@ func (h *UsersHandlers) GetLinks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	params := r.URL.Query()
+	query, err := parseLinksQuery(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cursor := params.Get("cursor")
+	paged := params.Has("limit") || cursor != ""
+
 	var responses []models.UserLinksResponse
 
 	status := http.StatusOK
 
-	results, err := h.usersService.GetLinks(ctx, r.Host)
+	results, next, err := h.usersService.GetLinks(ctx, r.Host, query, cursor)
 	if err != nil {
-		if errors.Is(err, services.ErrNoLinksByUser) {
+		switch {
+		case errors.Is(err, services.ErrNoLinksByUser):
 			status = http.StatusNoContent
-		} else {
+		case errors.Is(err, services.ErrInvalidQuery), errors.Is(err, services.ErrInvalidCursor):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		default:
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 	}
 
 	for _, result := range results {
-		response := models.UserLinksResponse(result)
-		responses = append(responses, response)
+		responses = append(responses, models.UserLinksResponse{
+			Shorten:  result.Shorten,
+			Original: result.Original,
+		})
 	}
 
-	resp, err := json.Marshal(responses)
+	var resp []byte
+	if paged {
+		resp, err = json.Marshal(models.UserLinksPageResponse{URLs: responses, NextCursor: next})
+	} else {
+		resp, err = json.Marshal(responses)
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -67,12 +99,107 @@ func (h *UsersHandlers) GetLinks(w http.ResponseWriter, r *http.Request) {
 	w.Write(resp)
 }
 
+// ExportLinks handles GET requests streaming all links of the currently-authenticated user, including soft-deleted ones,
+// as CSV, JSON or JSON Lines chosen with the "format" query parameter (JSON by default).
+// Links are written as they are read from storage; if reading fails midway, the connection is aborted
+// so that a truncated export is not mistaken for a complete one.
+//
+// Possible HTTP statuses:
+//   - 200 OK: Export streamed.
+//   - 400 Bad Request: Unsupported format.
+//   - 405 Method Not Allowed: Request method is not allowed (only GET supported).
+//   - 500 Internal Server Error: An internal error occurred before any link was exported.
+func (h *UsersHandlers) ExportLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "json"
+	}
+	format, ok := exportFormats[name]
+	if !ok {
+		http.Error(w, "The format parameter must be csv, json or jsonl", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("content-type", format.contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="links.`+name+`"`)
+	writer := format.newWriter(w)
+
+	var exported int
+	err := h.usersService.ExportLinks(ctx, r.Host, func(link models.ExportedLink) error {
+		exported++
+		return writer.Write(models.ExportLinkResponse(link))
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if exported == 0 {
+			w.Header().Del("Content-Disposition")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// parseLinksQuery builds a query for the user's links from the parameters of a listing request.
+func parseLinksQuery(params url.Values) (models.LinksQuery, error) {
+	query := models.LinksQuery{
+		SortBy: params.Get("sort"),
+		Origin: params.Get("origin"),
+	}
+
+	if value := params.Get("deleted"); value != "" {
+		deleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, errors.New("the deleted parameter must be a boolean")
+		}
+		query.Deleted = deleted
+	}
+	if params.Has("limit") {
+		limit, err := strconv.Atoi(params.Get("limit"))
+		if err != nil || limit < 1 || limit > constants.MaxLinksPageSize {
+			return query, fmt.Errorf("the limit parameter must be a number from 1 to %d", constants.MaxLinksPageSize)
+		}
+		query.Limit = limit
+	}
+	switch query.SortBy {
+	case "", constants.LinksSortCreated, constants.LinksSortClicks:
+	default:
+		return query, fmt.Errorf("the sort parameter must be %q or %q", constants.LinksSortCreated, constants.LinksSortClicks)
+	}
+	switch params.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, errors.New(`the order parameter must be "asc" or "desc"`)
+	}
+	if value := params.Get("created_after"); value != "" {
+		createdAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return query, errors.New("the created_after parameter must be an RFC 3339 timestamp")
+		}
+		query.CreatedAfter = createdAfter
+	}
+	return query, nil
+}
+
 // DeleteLinks processes DELETE requests for removing user-owned links.
+// The links are queued and deleted in the background after the response is sent.
 //
 // Possible HTTP statuses:
-//   - 202 Accepted: Deletion initiated successfully.
+//   - 202 Accepted: Deletion queued successfully.
 //   - 400 Bad Request: Invalid or missing request body.
-//   - 500 Internal Server Error: An internal error occurred during link deletion.
+//   - 503 Service Unavailable: The deletion queue is full; the request may be retried later.
+//   - 500 Internal Server Error: An internal error occurred while queueing the deletion.
 func (h *UsersHandlers) DeleteLinks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -91,10 +218,269 @@ func (h *UsersHandlers) DeleteLinks(w http.ResponseWriter, r *http.Request) {
 
 	err := h.usersService.DeleteLinks(ctx, shortLinks)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, services.ErrDeletionQueueFull) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
 	w.Header().Set("content-type", constants.TextContentType)
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// RestoreLinks processes POST requests undoing the soft-deletion of user-owned links.
+// Links that are not deleted, belong to other users or have already been purged are skipped.
+//
+// Possible HTTP statuses:
+//   - 200 OK: Links restored, the response reports how many.
+//   - 400 Bad Request: Invalid or missing request body.
+//   - 500 Internal Server Error: An internal error occurred during link restoration.
+func (h *UsersHandlers) RestoreLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	defer r.Body.Close()
+
+	var shortLinks []string
+	if err := json.NewDecoder(r.Body).Decode(&shortLinks); err != nil {
+		http.Error(w, "Couldn't parse the request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(shortLinks) == 0 {
+		http.Error(w, "The list of links is not provided", http.StatusBadRequest)
+		return
+	}
+
+	restored, err := h.usersService.RestoreLinks(ctx, shortLinks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(models.RestoreLinksResponse{Restored: restored})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", constants.JSONContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(resp)
+}
+
+// CreateAPIKey handles POST requests issuing a new named API key to the current user.
+// The plain key is returned only in this response.
+//
+// Possible HTTP statuses:
+//   - 201 Created: API key issued.
+//   - 400 Bad Request: Malformed request body or invalid key name.
+//   - 500 Internal Server Error: An internal error occurred while issuing the key.
+func (h *UsersHandlers) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	defer r.Body.Close()
+
+	var req models.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Couldn't parse the request body", http.StatusBadRequest)
+		return
+	}
+
+	apiKey, key, err := h.usersService.CreateAPIKey(ctx, req.Name)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidKeyName) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	response := newAPIKeyResponse(apiKey)
+	response.Key = key
+
+	resp, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", constants.JSONContentType)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// GetAPIKeys handles GET requests listing the API keys of the current user.
+//
+// Possible HTTP statuses:
+//   - 200 OK: Successfully fetched the user's API keys.
+//   - 500 Internal Server Error: An internal error occurred during retrieval.
+func (h *UsersHandlers) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	keys, err := h.usersService.GetAPIKeys(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	responses := make([]models.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, newAPIKeyResponse(key))
+	}
+
+	resp, err := json.Marshal(responses)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", constants.JSONContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(resp)
+}
+
+// RevokeAPIKey handles DELETE requests revoking an API key of the current user.
+//
+// Possible HTTP statuses:
+//   - 204 No Content: API key revoked.
+//   - 404 Not Found: The user has no API key with the given ID.
+//   - 500 Internal Server Error: An internal error occurred during revocation.
+func (h *UsersHandlers) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.usersService.RevokeAPIKey(ctx, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			http.Error(w, "API key not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newAPIKeyResponse converts an API key into its public representation without the key hash.
+func newAPIKeyResponse(key models.APIKey) models.APIKeyResponse {
+	return models.APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		CreatedAt: key.CreatedAt,
+	}
+}
+
+// Logout handles POST requests ending the current session.
+// The refresh token is revoked server-side and both session cookies are cleared.
+//
+// Possible HTTP statuses:
+//   - 204 No Content: Session ended.
+//   - 500 Internal Server Error: An internal error occurred while revoking the session.
+func (h *UsersHandlers) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if cookie, err := r.Cookie(constants.RefreshTokenCookie); err == nil {
+		if err := h.usersService.Logout(ctx, cookie.Value); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	for _, name := range []string{constants.AccessTokenCookie, constants.RefreshTokenCookie} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Path:     "/",
+			HttpOnly: true,
+			MaxAge:   -1,
+		})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Register handles POST requests creating an account with a username and password.
+// An anonymous user making the request becomes the account, keeping its links; the client is signed in to the account.
+//
+// Possible HTTP statuses:
+//   - 201 Created: Account created and signed in.
+//   - 400 Bad Request: The body is malformed or the username or password is invalid.
+//   - 409 Conflict: The username is already taken.
+//   - 500 Internal Server Error: An internal error occurred during registration.
+func (h *UsersHandlers) Register(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	defer r.Body.Close()
+
+	var req models.CredentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Couldn't parse the request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := h.usersService.Register(ctx, req.Username, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidUsername), errors.Is(err, services.ErrInvalidPassword):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrUsernameTaken):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	h.signIn(w, r, userID, req.Username, http.StatusCreated)
+}
+
+// Login handles POST requests signing in to an account with a username and password.
+// Links of an anonymous user making the request are claimed into the account.
+//
+// Possible HTTP statuses:
+//   - 200 OK: Signed in.
+//   - 400 Bad Request: The body is malformed.
+//   - 401 Unauthorized: The username or password is wrong.
+//   - 500 Internal Server Error: An internal error occurred while signing in.
+func (h *UsersHandlers) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	defer r.Body.Close()
+
+	var req models.CredentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Couldn't parse the request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := h.usersService.Authenticate(ctx, req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrBadCredentials) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	h.signIn(w, r, userID, req.Username, http.StatusOK)
+}
+
+// signIn starts a session of the account and writes the account description with the given status.
+func (h *UsersHandlers) signIn(w http.ResponseWriter, r *http.Request, userID int64, username string, status int) {
+	if err := middleware.StartSession(w, r, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(models.AccountResponse{Username: strings.ToLower(username)})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", constants.JSONContentType)
+	w.WriteHeader(status)
+	w.Write(resp)
+}
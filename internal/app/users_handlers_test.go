@@ -0,0 +1,333 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"main/internal/adapters"
+	"main/internal/constants"
+	"main/internal/middleware"
+	"main/internal/mocks"
+	"main/internal/models"
+	"main/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestUsersHandlers returns users handlers over a fresh repository, along with the repository.
+func newTestUsersHandlers(t *testing.T) (*UsersHandlers, *Repository) {
+	r, err := NewRepository(newTestConfig(t), adapters.GetLogger())
+	require.NoError(t, err)
+
+	usersService := services.NewUserService(r.users, nil)
+	middleware.UserService = usersService
+	middleware.Keys, err = middleware.NewKeySet("secret", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { middleware.UserService = nil })
+
+	return NewUsersHandlers(usersService), r
+}
+
+// credentialsRequest builds a request with the given credentials, made by the user if it is not zero.
+func credentialsRequest(target, body string, userID int64) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	if userID != 0 {
+		request = request.WithContext(context.WithValue(request.Context(), constants.UserIDKey, userID))
+	}
+	return request
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	tests := []struct {
+		name       string
+		register   bool
+		body       string
+		wantStatus int
+	}{
+		{name: "register", register: true, body: `{"username":"Bob","password":"password2"}`, wantStatus: http.StatusCreated},
+		{name: "register taken username", register: true, body: `{"username":"ALICE","password":"password2"}`, wantStatus: http.StatusConflict},
+		{name: "register short password", register: true, body: `{"username":"carol","password":"short"}`, wantStatus: http.StatusBadRequest},
+		{name: "register malformed body", register: true, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "login", body: `{"username":"Alice","password":"password1"}`, wantStatus: http.StatusOK},
+		{name: "login wrong password", body: `{"username":"alice","password":"password2"}`, wantStatus: http.StatusUnauthorized},
+		{name: "login unknown user", body: `{"username":"nobody","password":"password1"}`, wantStatus: http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h, _ := newTestUsersHandlers(t)
+
+			w := httptest.NewRecorder()
+			h.Register(w, credentialsRequest("/api/user/register", `{"username":"alice","password":"password1"}`, 0))
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			w = httptest.NewRecorder()
+			if test.register {
+				h.Register(w, credentialsRequest("/api/user/register", test.body, 0))
+			} else {
+				h.Login(w, credentialsRequest("/api/user/login", test.body, 0))
+			}
+
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+
+			var signedIn bool
+			for _, cookie := range res.Cookies() {
+				signedIn = signedIn || cookie.Name == constants.AccessTokenCookie
+			}
+			assert.Equal(t, test.wantStatus < http.StatusBadRequest, signedIn, "only successful requests start a session")
+		})
+	}
+}
+
+func TestRegisterAndLoginClaimLinks(t *testing.T) {
+	h, r := newTestUsersHandlers(t)
+	ctx := context.Background()
+
+	addLinks := func(userID int64, shorts ...string) {
+		userCtx := context.WithValue(ctx, constants.UserIDKey, userID)
+		for _, short := range shorts {
+			_, err := r.links.Add(userCtx, models.AddedLink{Short: short, Origin: "https://test.com/" + short})
+			require.NoError(t, err)
+		}
+	}
+	linksOf := func(userID int64) int {
+		links, _ := r.users.GetLinks(context.WithValue(ctx, constants.UserIDKey, userID), models.LinksQuery{})
+		return len(links)
+	}
+
+	// Registering turns the anonymous user into the account, keeping its links.
+	accountID, err := r.users.Login(ctx)
+	require.NoError(t, err)
+	addLinks(accountID, "first")
+
+	w := httptest.NewRecorder()
+	h.Register(w, credentialsRequest("/api/user/register", `{"username":"alice","password":"password1"}`, accountID))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// Signing in claims the links of another anonymous user into the account.
+	anonymousID, err := r.users.Login(ctx)
+	require.NoError(t, err)
+	addLinks(anonymousID, "second", "third")
+
+	w = httptest.NewRecorder()
+	h.Login(w, credentialsRequest("/api/user/login", `{"username":"alice","password":"password1"}`, anonymousID))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, 3, linksOf(accountID))
+	assert.Zero(t, linksOf(anonymousID))
+
+	// A failed sign-in leaves the links where they are.
+	otherID, err := r.users.Login(ctx)
+	require.NoError(t, err)
+	addLinks(otherID, "fourth")
+
+	w = httptest.NewRecorder()
+	h.Login(w, credentialsRequest("/api/user/login", `{"username":"alice","password":"wrong password"}`, otherID))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	assert.Equal(t, 3, linksOf(accountID))
+	assert.Equal(t, 1, linksOf(otherID))
+}
+
+func TestLogout(t *testing.T) {
+	tests := []struct {
+		name    string
+		session bool
+	}{
+		{name: "with session", session: true},
+		{name: "without session"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h, r := newTestUsersHandlers(t)
+			ctx := context.Background()
+
+			userID, err := r.users.Login(ctx)
+			require.NoError(t, err)
+			token, err := middleware.UserService.IssueRefreshToken(ctx, userID)
+			require.NoError(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/user/logout", nil)
+			if test.session {
+				request.AddCookie(&http.Cookie{Name: constants.RefreshTokenCookie, Value: token})
+			}
+			w := httptest.NewRecorder()
+			h.Logout(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+			cleared := make(map[string]bool)
+			for _, cookie := range res.Cookies() {
+				cleared[cookie.Name] = cookie.MaxAge < 0
+			}
+			assert.True(t, cleared[constants.AccessTokenCookie])
+			assert.True(t, cleared[constants.RefreshTokenCookie])
+
+			_, err = middleware.UserService.RefreshSession(ctx, token)
+			if test.session {
+				assert.ErrorIs(t, err, services.ErrSessionNotFound, "the session is revoked server-side")
+			} else {
+				assert.NoError(t, err, "other sessions are left alone")
+			}
+		})
+	}
+}
+
+func TestExportLinks(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	exported := []models.ExportedLink{
+		{Short: "a", ShortURL: "http://localhost/a", Original: "https://test.com/a", CreatedAt: createdAt, Clicks: 2},
+		{Short: "b", ShortURL: "http://localhost/b", Original: "https://test.com/b", CreatedAt: createdAt, Deleted: true},
+	}
+	failure := errors.New("connection reset")
+
+	type want struct {
+		contentType string
+		statusCode  int
+		body        string
+		aborted     bool
+	}
+	tests := []struct {
+		name   string
+		format string
+		links  []models.ExportedLink
+		err    error
+		want   want
+	}{
+		{
+			name:   "csv",
+			format: "csv",
+			links:  exported,
+			want: want{
+				contentType: constants.CSVContentType,
+				statusCode:  http.StatusOK,
+				body: "short,short_url,original_url,created_at,deleted,clicks\n" +
+					"a,http://localhost/a,https://test.com/a,2024-01-02T03:04:05Z,false,2\n" +
+					"b,http://localhost/b,https://test.com/b,2024-01-02T03:04:05Z,true,0\n",
+			},
+		},
+		{
+			name:   "empty csv",
+			format: "csv",
+			want: want{
+				contentType: constants.CSVContentType,
+				statusCode:  http.StatusOK,
+				body:        "short,short_url,original_url,created_at,deleted,clicks\n",
+			},
+		},
+		{
+			name:   "json",
+			format: "json",
+			links:  exported,
+			want: want{
+				contentType: constants.JSONContentType,
+				statusCode:  http.StatusOK,
+				body: `[{"short":"a","short_url":"http://localhost/a","original_url":"https://test.com/a","created_at":"2024-01-02T03:04:05Z","deleted":false,"clicks":2},` +
+					`{"short":"b","short_url":"http://localhost/b","original_url":"https://test.com/b","created_at":"2024-01-02T03:04:05Z","deleted":true,"clicks":0}]`,
+			},
+		},
+		{
+			name: "empty json by default",
+			want: want{
+				contentType: constants.JSONContentType,
+				statusCode:  http.StatusOK,
+				body:        "[]",
+			},
+		},
+		{
+			name:   "json lines",
+			format: "jsonl",
+			links:  exported,
+			want: want{
+				contentType: constants.JSONLinesContentType,
+				statusCode:  http.StatusOK,
+				body: `{"short":"a","short_url":"http://localhost/a","original_url":"https://test.com/a","created_at":"2024-01-02T03:04:05Z","deleted":false,"clicks":2}` + "\n" +
+					`{"short":"b","short_url":"http://localhost/b","original_url":"https://test.com/b","created_at":"2024-01-02T03:04:05Z","deleted":true,"clicks":0}` + "\n",
+			},
+		},
+		{
+			name:   "unknown format",
+			format: "xml",
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusBadRequest,
+			},
+		},
+		{
+			name:   "failure before any link",
+			format: "csv",
+			err:    failure,
+			want: want{
+				contentType: constants.TextContentType,
+				statusCode:  http.StatusInternalServerError,
+			},
+		},
+		{
+			name:   "failure after some links",
+			format: "json",
+			links:  exported[:1],
+			err:    failure,
+			want: want{
+				aborted: true,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			usersService := mocks.NewMockUsersService(ctrl)
+			if test.want.statusCode != http.StatusBadRequest {
+				usersService.EXPECT().ExportLinks(gomock.Any(), "example.com", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, host string, yield func(models.ExportedLink) error) error {
+						for _, link := range test.links {
+							if err := yield(link); err != nil {
+								return err
+							}
+						}
+						return test.err
+					})
+			}
+			h := NewUsersHandlers(usersService)
+
+			target := "/api/user/export"
+			if test.format != "" {
+				target += "?format=" + test.format
+			}
+			request := httptest.NewRequest(http.MethodGet, target, nil)
+			w := httptest.NewRecorder()
+
+			if test.want.aborted {
+				assert.PanicsWithValue(t, http.ErrAbortHandler, func() { h.ExportLinks(w, request) },
+					"a truncated export must abort the connection")
+				return
+			}
+			h.ExportLinks(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.statusCode, res.StatusCode)
+			assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"))
+			if test.want.body != "" {
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, test.want.body, string(body))
+			}
+			if test.want.statusCode != http.StatusOK {
+				assert.Empty(t, res.Header.Get("Content-Disposition"))
+			}
+		})
+	}
+}
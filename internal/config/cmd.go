@@ -5,16 +5,30 @@ import (
 	"flag"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // cmdConfig holds configuration settings obtained from command-line flags.
 type cmdConfig struct {
-	Addr             string // Command-line argument for server address.
-	StorageFilePaths string // Command-line option specifying file storage paths.
-	ShortLinkPrefix  string // Base URL for short links passed via command-line.
-	PostgresDSN      string // Postgres DSN given on the command line.
-	HTTPSEnable      string // Indicates whether HTTPS is enabled for the server.
-	ConfFile         string // Name of the configuration file.
+	Addr              string        // Command-line argument for server address.
+	StorageFilePaths  string        // Command-line option specifying file storage paths.
+	ShortLinkPrefix   string        // Base URL for short links passed via command-line.
+	PostgresDSN       string        // Postgres DSN given on the command line.
+	HTTPSEnable       string        // Indicates whether HTTPS is enabled for the server.
+	ConfFile          string        // Name of the configuration file.
+	ShortCodeStrategy string        // Strategy for generating short codes.
+	ShortCodeLength   int           // Length of generated short codes.
+	JWTSecret         string        // Shared secret for signing access tokens.
+	JWTKeysFile       string        // Path to the JSON key set file for signing access tokens.
+	AuthPolicy        string        // Authentication policy of the link creation routes.
+	DeletedRetention  time.Duration // Time soft-deleted links can be restored before being purged.
+	DomainPolicyFile  string        // Path to the domain policy file.
+	CreateRateLimit   string        // Rate limit of the link creation routes per user or IP.
+	RedirectRateLimit string        // Rate limit of redirects per user or IP.
+	LinksCacheSize    int           // Number of links kept in the redirect cache.
+	LinksCacheTTL     time.Duration // Time links are kept in the redirect cache.
+	TrustedProxies    string        // Comma-separated addresses or CIDR ranges of trusted reverse proxies.
+	IPHashSecret      string        // Secret keying the hashes of client IP addresses.
 }
 
 // servHost encapsulates information about the network service's host and port.
@@ -35,6 +49,19 @@ func parseCmd() (*cmdConfig, error) {
 	flag.StringVar(&cfg.StorageFilePaths, "f", "", "Path to storage file")
 	flag.StringVar(&cfg.HTTPSEnable, "s", "0", "HTTPS is enabled")
 	flag.StringVar(&cfg.ConfFile, "c", "", "Name of the configuration file")
+	flag.StringVar(&cfg.ShortCodeStrategy, "g", "", "Short code generation strategy (random, counter, uuid)")
+	flag.IntVar(&cfg.ShortCodeLength, "l", 0, "Length of generated short codes")
+	flag.StringVar(&cfg.JWTSecret, "j", "", "Shared secret for signing access tokens")
+	flag.StringVar(&cfg.JWTKeysFile, "k", "", "Path to the JWT key set file")
+	flag.StringVar(&cfg.AuthPolicy, "p", "", "Authentication policy of the link creation routes (lazy, required)")
+	flag.DurationVar(&cfg.DeletedRetention, "r", 0, "Time soft-deleted links can be restored before being purged")
+	flag.StringVar(&cfg.DomainPolicyFile, "m", "", "Path to the domain policy file")
+	flag.StringVar(&cfg.CreateRateLimit, "w", "", "Rate limit of the link creation routes per user or IP, e.g. 100/1m or off")
+	flag.StringVar(&cfg.RedirectRateLimit, "o", "", "Rate limit of redirects per user or IP, e.g. 1000/1m or off")
+	flag.IntVar(&cfg.LinksCacheSize, "n", 0, "Number of links kept in the redirect cache, negative to disable it")
+	flag.DurationVar(&cfg.LinksCacheTTL, "t", 0, "Time links are kept in the redirect cache")
+	flag.StringVar(&cfg.TrustedProxies, "i", "", "Comma-separated addresses or CIDR ranges of trusted reverse proxies")
+	flag.StringVar(&cfg.IPHashSecret, "e", "", "Secret keying the hashes of client IP addresses stored with clicks")
 	flag.Var(hostPort, "a", "Network address host:port")
 	flag.Parse()
 
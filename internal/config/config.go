@@ -3,23 +3,45 @@ package config
 import (
 	"go.uber.org/zap"
 	"net/url"
+	"time"
 )
 
 const (
-	defaultStorageFilePath = "shorter"        // Default path for storage file if no custom path is provided.
-	defaultPProfAddr       = "localhost:6060" // Address for pprof profiling endpoint.
-	defaultConfFileName    = "conf.json"      // Name of the configuration file in json format
+	defaultStorageFilePath   = "shorter"           // Default path for storage file if no custom path is provided.
+	defaultPProfAddr         = "localhost:6060"    // Address for pprof profiling endpoint.
+	defaultConfFileName      = "conf.json"         // Name of the configuration file in json format
+	defaultShortCodeStrategy = "random"            // Default strategy for generating short codes.
+	defaultShortCodeLength   = 8                   // Default length of generated short codes.
+	defaultAuthPolicy        = "lazy"              // Default authentication policy of the link creation routes.
+	defaultDeletedRetention  = 30 * 24 * time.Hour // Default time soft-deleted links can be restored before being purged.
+	defaultCreateRateLimit   = "100/1m"            // Default rate limit of the link creation routes.
+	defaultRedirectRateLimit = "1000/1m"           // Default rate limit of redirects.
+	defaultLinksCacheSize    = 10000               // Default number of links kept in the redirect cache.
+	defaultLinksCacheTTL     = time.Minute         // Default time links are kept in the redirect cache.
 )
 
 // Config stores all the necessary configurations from both environment variables and command line inputs.
 type Config struct {
-	PostgresDSN      *url.URL // Database connection details (Data Source Name).
-	PProfAddr        string   // Address for pprof profiling endpoint.
-	Addr             string   // Server listening address.
-	ShortLinkPrefix  string   // Base URL for short links.
-	StorageFilePaths string   // Path where storage files are located.
-	ExecutableDir    string   // Project directory
-	HTTPSEnable      bool     // Indicates whether HTTPS is enabled for the server.
+	PostgresDSN       *url.URL      // Database connection details (Data Source Name).
+	PProfAddr         string        // Address for pprof profiling endpoint.
+	Addr              string        // Server listening address.
+	ShortLinkPrefix   string        // Base URL for short links.
+	StorageFilePaths  string        // Path where storage files are located.
+	ExecutableDir     string        // Project directory
+	HTTPSEnable       bool          // Indicates whether HTTPS is enabled for the server.
+	ShortCodeStrategy string        // Strategy for generating short codes ("random", "counter" or "uuid").
+	ShortCodeLength   int           // Length of generated short codes.
+	JWTSecret         string        // Shared secret for signing access tokens with HS256.
+	JWTKeysFile       string        // Path to the JSON key set file; takes precedence over JWTSecret.
+	AuthPolicy        string        // Authentication policy of the link creation routes ("lazy" or "required").
+	DeletedRetention  time.Duration // Time soft-deleted links can be restored before being purged.
+	DomainPolicyFile  string        // Path to the JSON domain policy file; every domain is allowed if empty.
+	CreateRateLimit   string        // Rate limit of the link creation routes per user or IP ("<requests>/<period>" or "off").
+	RedirectRateLimit string        // Rate limit of redirects per user or IP ("<requests>/<period>" or "off").
+	LinksCacheSize    int           // Number of links kept in the redirect cache of the PostgreSQL storage; negative disables the cache.
+	LinksCacheTTL     time.Duration // Time links are kept in the redirect cache before being looked up again.
+	TrustedProxies    string        // Comma-separated addresses or CIDR ranges of reverse proxies whose forwarding headers are trusted.
+	IPHashSecret      string        // Secret keying the hashes of client IP addresses stored with clicks.
 }
 
 // Parse merges environment variables and command-line options into a single configuration object.
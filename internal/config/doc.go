@@ -2,12 +2,25 @@
 //
 // environment variables:
 //
-//	FILE_STORAGE_PATH | File storage paths specified via an environment variable.
-//	SERVER_ADDRESS    | Server address defined by an environment variable.
-//	BASE_URL          | Short link base URL configured via an environment variable.
-//	DATABASE_DSN      | PostgreSQL Data Source Name received from an environment variable.
-//	ENABLE_HTTPS      | Indicates whether HTTPS is enabled for the server.
-//	CONFIG      	  | Name of the configuration file.
+//	FILE_STORAGE_PATH       | File storage paths specified via an environment variable.
+//	SERVER_ADDRESS          | Server address defined by an environment variable.
+//	BASE_URL                | Short link base URL configured via an environment variable.
+//	DATABASE_DSN            | PostgreSQL Data Source Name received from an environment variable.
+//	ENABLE_HTTPS            | Indicates whether HTTPS is enabled for the server.
+//	CONFIG                  | Name of the configuration file.
+//	SHORT_CODE_STRATEGY     | Short code generation strategy ("random", "counter" or "uuid").
+//	SHORT_CODE_LENGTH       | Length of generated short codes.
+//	JWT_SECRET              | Shared secret for signing access tokens with HS256.
+//	JWT_KEYS_FILE           | Path to the JSON key set file for signing access tokens (takes precedence over JWT_SECRET).
+//	AUTH_POLICY             | Authentication policy of the link creation routes ("lazy" creates anonymous users, "required" does not).
+//	DELETED_LINKS_RETENTION | Time soft-deleted links can be restored before being purged, e.g. "720h" (30 days by default).
+//	DOMAIN_POLICY_FILE      | Path to the JSON domain policy file blocking or allowing link destinations, reloaded on change.
+//	CREATE_RATE_LIMIT       | Rate limit of the link creation routes per user or IP, e.g. "100/1m" (default) or "off".
+//	REDIRECT_RATE_LIMIT     | Rate limit of redirects per user or IP, e.g. "1000/1m" (default) or "off".
+//	LINKS_CACHE_SIZE        | Number of links kept in the redirect cache of the PostgreSQL storage (10000 by default, negative to disable it).
+//	LINKS_CACHE_TTL         | Time links are kept in the redirect cache, e.g. "1m" (default).
+//	TRUSTED_PROXIES         | Comma-separated addresses or CIDR ranges of reverse proxies whose X-Forwarded-For headers are trusted (none by default).
+//	IP_HASH_SECRET          | Secret keying the hashes of client IP addresses stored with clicks (random per start by default).
 //
 // command-line arguments:
 //
@@ -17,14 +30,40 @@
 //	-d | Postgres DSN given on the command line.
 //	-s | Indicates whether HTTPS is enabled for the server ("true", "yes", "1" -> true, "false", "no", "0" -> false).
 //	-c | Name of the configuration file.
+//	-g | Short code generation strategy ("random", "counter" or "uuid").
+//	-l | Length of generated short codes.
+//	-j | Shared secret for signing access tokens with HS256.
+//	-k | Path to the JSON key set file for signing access tokens.
+//	-p | Authentication policy of the link creation routes ("lazy" or "required").
+//	-r | Time soft-deleted links can be restored before being purged, e.g. "720h".
+//	-m | Path to the JSON domain policy file blocking or allowing link destinations.
+//	-w | Rate limit of the link creation routes per user or IP, e.g. "100/1m" or "off".
+//	-o | Rate limit of redirects per user or IP, e.g. "1000/1m" or "off".
+//	-n | Number of links kept in the redirect cache of the PostgreSQL storage, negative to disable it.
+//	-t | Time links are kept in the redirect cache, e.g. "1m".
+//	-i | Comma-separated addresses or CIDR ranges of reverse proxies whose X-Forwarded-For headers are trusted.
+//	-e | Secret keying the hashes of client IP addresses stored with clicks.
 //
 // config file:
 //
 //	config.json | Configuration file in JSON format.
 //
-//	file_storage_path | File storage paths specified via an environment variable.
-//	server_address    | Server address defined by an environment variable.
-//	base_url          | Short link base URL configured via an environment variable.
-//	database_dsn      | PostgreSQL Data Source Name received from an environment variable.
-//	enable_https      | Indicates whether HTTPS is enabled for the server.
+//	file_storage_path       | File storage paths specified via an environment variable.
+//	server_address          | Server address defined by an environment variable.
+//	base_url                | Short link base URL configured via an environment variable.
+//	database_dsn            | PostgreSQL Data Source Name received from an environment variable.
+//	enable_https            | Indicates whether HTTPS is enabled for the server.
+//	short_code_strategy     | Short code generation strategy ("random", "counter" or "uuid").
+//	short_code_length       | Length of generated short codes.
+//	jwt_secret              | Shared secret for signing access tokens with HS256.
+//	jwt_keys_file           | Path to the JSON key set file for signing access tokens.
+//	auth_policy             | Authentication policy of the link creation routes ("lazy" or "required").
+//	deleted_links_retention | Time soft-deleted links can be restored before being purged, e.g. "720h".
+//	domain_policy_file      | Path to the JSON domain policy file blocking or allowing link destinations.
+//	create_rate_limit       | Rate limit of the link creation routes per user or IP, e.g. "100/1m" or "off".
+//	redirect_rate_limit     | Rate limit of redirects per user or IP, e.g. "1000/1m" or "off".
+//	links_cache_size        | Number of links kept in the redirect cache of the PostgreSQL storage, negative to disable it.
+//	links_cache_ttl         | Time links are kept in the redirect cache, e.g. "1m".
+//	trusted_proxies         | Comma-separated addresses or CIDR ranges of reverse proxies whose X-Forwarded-For headers are trusted.
+//	ip_hash_secret          | Secret keying the hashes of client IP addresses stored with clicks.
 package config
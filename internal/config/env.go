@@ -2,16 +2,30 @@ package config
 
 import (
 	"github.com/caarlos0/env/v6"
+	"time"
 )
 
 // envConfig holds configuration settings retrieved from environment variables.
 type envConfig struct {
-	StorageFilePaths string `env:"FILE_STORAGE_PATH"` // File storage paths specified via an environment variable.
-	Addr             string `env:"SERVER_ADDRESS"`    // Server address defined by an environment variable.
-	ShortLinkPrefix  string `env:"BASE_URL"`          // Short link base URL configured via an environment variable.
-	PostgresDSN      string `env:"DATABASE_DSN"`      // PostgreSQL Data Source Name received from an environment variable.
-	HTTPSEnable      string `env:"ENABLE_HTTPS"`      // Indicates whether HTTPS is enabled for the server.
-	ConfFile         string `env:"CONFIG"`            // Name of the configuration file.
+	StorageFilePaths  string        `env:"FILE_STORAGE_PATH"`       // File storage paths specified via an environment variable.
+	Addr              string        `env:"SERVER_ADDRESS"`          // Server address defined by an environment variable.
+	ShortLinkPrefix   string        `env:"BASE_URL"`                // Short link base URL configured via an environment variable.
+	PostgresDSN       string        `env:"DATABASE_DSN"`            // PostgreSQL Data Source Name received from an environment variable.
+	HTTPSEnable       string        `env:"ENABLE_HTTPS"`            // Indicates whether HTTPS is enabled for the server.
+	ConfFile          string        `env:"CONFIG"`                  // Name of the configuration file.
+	ShortCodeStrategy string        `env:"SHORT_CODE_STRATEGY"`     // Strategy for generating short codes.
+	ShortCodeLength   int           `env:"SHORT_CODE_LENGTH"`       // Length of generated short codes.
+	JWTSecret         string        `env:"JWT_SECRET"`              // Shared secret for signing access tokens.
+	JWTKeysFile       string        `env:"JWT_KEYS_FILE"`           // Path to the JSON key set file for signing access tokens.
+	AuthPolicy        string        `env:"AUTH_POLICY"`             // Authentication policy of the link creation routes.
+	DeletedRetention  time.Duration `env:"DELETED_LINKS_RETENTION"` // Time soft-deleted links can be restored before being purged.
+	DomainPolicyFile  string        `env:"DOMAIN_POLICY_FILE"`      // Path to the domain policy file.
+	CreateRateLimit   string        `env:"CREATE_RATE_LIMIT"`       // Rate limit of the link creation routes per user or IP.
+	RedirectRateLimit string        `env:"REDIRECT_RATE_LIMIT"`     // Rate limit of redirects per user or IP.
+	LinksCacheSize    int           `env:"LINKS_CACHE_SIZE"`        // Number of links kept in the redirect cache.
+	LinksCacheTTL     time.Duration `env:"LINKS_CACHE_TTL"`         // Time links are kept in the redirect cache.
+	TrustedProxies    string        `env:"TRUSTED_PROXIES"`         // Comma-separated addresses or CIDR ranges of trusted reverse proxies.
+	IPHashSecret      string        `env:"IP_HASH_SECRET"`          // Secret keying the hashes of client IP addresses.
 }
 
 // parseEnv extracts configuration from environment variables.
@@ -8,11 +8,24 @@ import (
 
 // JSONConfig represents the structure of the JSON configuration file.
 type JSONConfig struct {
-	StorageFilePaths string `json:"file_storage_path,omitempty"`
-	Addr             string `json:"server_address,omitempty"`
-	ShortLinkPrefix  string `json:"base_url,omitempty"`
-	PostgresDSN      string `json:"database_dsn,omitempty"`
-	HTTPSEnable      bool   `json:"enable_https,omitempty"`
+	StorageFilePaths  string `json:"file_storage_path,omitempty"`
+	Addr              string `json:"server_address,omitempty"`
+	ShortLinkPrefix   string `json:"base_url,omitempty"`
+	PostgresDSN       string `json:"database_dsn,omitempty"`
+	HTTPSEnable       bool   `json:"enable_https,omitempty"`
+	ShortCodeStrategy string `json:"short_code_strategy,omitempty"`
+	ShortCodeLength   int    `json:"short_code_length,omitempty"`
+	JWTSecret         string `json:"jwt_secret,omitempty"`
+	JWTKeysFile       string `json:"jwt_keys_file,omitempty"`
+	AuthPolicy        string `json:"auth_policy,omitempty"`
+	DeletedRetention  string `json:"deleted_links_retention,omitempty"`
+	DomainPolicyFile  string `json:"domain_policy_file,omitempty"`
+	CreateRateLimit   string `json:"create_rate_limit,omitempty"`
+	RedirectRateLimit string `json:"redirect_rate_limit,omitempty"`
+	LinksCacheSize    int    `json:"links_cache_size,omitempty"`
+	LinksCacheTTL     string `json:"links_cache_ttl,omitempty"`
+	TrustedProxies    string `json:"trusted_proxies,omitempty"`
+	IPHashSecret      string `json:"ip_hash_secret,omitempty"`
 }
 
 // parseJSON reads and parses the JSON configuration file from the given directory.
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // mergeConfigs merges configuration values with priority: environment > command line > JSON file.
@@ -55,12 +56,140 @@ func mergeConfigs(exeDir string, envCfg *envConfig, cmdCfg *cmdConfig, jsonCfg *
 		finalConfig.HTTPSEnable = jsonCfg.HTTPSEnable
 	}
 
+	if envCfg.ShortCodeStrategy != "" {
+		finalConfig.ShortCodeStrategy = envCfg.ShortCodeStrategy
+	} else if cmdCfg.ShortCodeStrategy != "" {
+		finalConfig.ShortCodeStrategy = cmdCfg.ShortCodeStrategy
+	} else if jsonCfg.ShortCodeStrategy != "" {
+		finalConfig.ShortCodeStrategy = jsonCfg.ShortCodeStrategy
+	}
+
+	if envCfg.ShortCodeLength != 0 {
+		finalConfig.ShortCodeLength = envCfg.ShortCodeLength
+	} else if cmdCfg.ShortCodeLength != 0 {
+		finalConfig.ShortCodeLength = cmdCfg.ShortCodeLength
+	} else if jsonCfg.ShortCodeLength != 0 {
+		finalConfig.ShortCodeLength = jsonCfg.ShortCodeLength
+	}
+
+	if envCfg.JWTSecret != "" {
+		finalConfig.JWTSecret = envCfg.JWTSecret
+	} else if cmdCfg.JWTSecret != "" {
+		finalConfig.JWTSecret = cmdCfg.JWTSecret
+	} else if jsonCfg.JWTSecret != "" {
+		finalConfig.JWTSecret = jsonCfg.JWTSecret
+	}
+
+	if envCfg.JWTKeysFile != "" {
+		finalConfig.JWTKeysFile = envCfg.JWTKeysFile
+	} else if cmdCfg.JWTKeysFile != "" {
+		finalConfig.JWTKeysFile = cmdCfg.JWTKeysFile
+	} else if jsonCfg.JWTKeysFile != "" {
+		finalConfig.JWTKeysFile = jsonCfg.JWTKeysFile
+	}
+
+	if envCfg.AuthPolicy != "" {
+		finalConfig.AuthPolicy = envCfg.AuthPolicy
+	} else if cmdCfg.AuthPolicy != "" {
+		finalConfig.AuthPolicy = cmdCfg.AuthPolicy
+	} else if jsonCfg.AuthPolicy != "" {
+		finalConfig.AuthPolicy = jsonCfg.AuthPolicy
+	}
+
+	if envCfg.DeletedRetention != 0 {
+		finalConfig.DeletedRetention = envCfg.DeletedRetention
+	} else if cmdCfg.DeletedRetention != 0 {
+		finalConfig.DeletedRetention = cmdCfg.DeletedRetention
+	} else if jsonCfg.DeletedRetention != "" {
+		finalConfig.DeletedRetention, _ = time.ParseDuration(jsonCfg.DeletedRetention)
+	}
+
+	if envCfg.DomainPolicyFile != "" {
+		finalConfig.DomainPolicyFile = envCfg.DomainPolicyFile
+	} else if cmdCfg.DomainPolicyFile != "" {
+		finalConfig.DomainPolicyFile = cmdCfg.DomainPolicyFile
+	} else if jsonCfg.DomainPolicyFile != "" {
+		finalConfig.DomainPolicyFile = jsonCfg.DomainPolicyFile
+	}
+
+	if envCfg.CreateRateLimit != "" {
+		finalConfig.CreateRateLimit = envCfg.CreateRateLimit
+	} else if cmdCfg.CreateRateLimit != "" {
+		finalConfig.CreateRateLimit = cmdCfg.CreateRateLimit
+	} else if jsonCfg.CreateRateLimit != "" {
+		finalConfig.CreateRateLimit = jsonCfg.CreateRateLimit
+	}
+
+	if envCfg.RedirectRateLimit != "" {
+		finalConfig.RedirectRateLimit = envCfg.RedirectRateLimit
+	} else if cmdCfg.RedirectRateLimit != "" {
+		finalConfig.RedirectRateLimit = cmdCfg.RedirectRateLimit
+	} else if jsonCfg.RedirectRateLimit != "" {
+		finalConfig.RedirectRateLimit = jsonCfg.RedirectRateLimit
+	}
+
+	if envCfg.LinksCacheSize != 0 {
+		finalConfig.LinksCacheSize = envCfg.LinksCacheSize
+	} else if cmdCfg.LinksCacheSize != 0 {
+		finalConfig.LinksCacheSize = cmdCfg.LinksCacheSize
+	} else if jsonCfg.LinksCacheSize != 0 {
+		finalConfig.LinksCacheSize = jsonCfg.LinksCacheSize
+	}
+
+	if envCfg.LinksCacheTTL != 0 {
+		finalConfig.LinksCacheTTL = envCfg.LinksCacheTTL
+	} else if cmdCfg.LinksCacheTTL != 0 {
+		finalConfig.LinksCacheTTL = cmdCfg.LinksCacheTTL
+	} else if jsonCfg.LinksCacheTTL != "" {
+		finalConfig.LinksCacheTTL, _ = time.ParseDuration(jsonCfg.LinksCacheTTL)
+	}
+
+	if envCfg.TrustedProxies != "" {
+		finalConfig.TrustedProxies = envCfg.TrustedProxies
+	} else if cmdCfg.TrustedProxies != "" {
+		finalConfig.TrustedProxies = cmdCfg.TrustedProxies
+	} else if jsonCfg.TrustedProxies != "" {
+		finalConfig.TrustedProxies = jsonCfg.TrustedProxies
+	}
+
+	if envCfg.IPHashSecret != "" {
+		finalConfig.IPHashSecret = envCfg.IPHashSecret
+	} else if cmdCfg.IPHashSecret != "" {
+		finalConfig.IPHashSecret = cmdCfg.IPHashSecret
+	} else if jsonCfg.IPHashSecret != "" {
+		finalConfig.IPHashSecret = jsonCfg.IPHashSecret
+	}
+
 	finalConfig.PProfAddr = defaultPProfAddr
 	finalConfig.ExecutableDir = exeDir
 
 	if finalConfig.StorageFilePaths == "" {
 		finalConfig.StorageFilePaths = defaultStorageFilePath
 	}
+	if finalConfig.ShortCodeStrategy == "" {
+		finalConfig.ShortCodeStrategy = defaultShortCodeStrategy
+	}
+	if finalConfig.ShortCodeLength == 0 {
+		finalConfig.ShortCodeLength = defaultShortCodeLength
+	}
+	if finalConfig.AuthPolicy == "" {
+		finalConfig.AuthPolicy = defaultAuthPolicy
+	}
+	if finalConfig.DeletedRetention <= 0 {
+		finalConfig.DeletedRetention = defaultDeletedRetention
+	}
+	if finalConfig.CreateRateLimit == "" {
+		finalConfig.CreateRateLimit = defaultCreateRateLimit
+	}
+	if finalConfig.RedirectRateLimit == "" {
+		finalConfig.RedirectRateLimit = defaultRedirectRateLimit
+	}
+	if finalConfig.LinksCacheSize == 0 {
+		finalConfig.LinksCacheSize = defaultLinksCacheSize
+	}
+	if finalConfig.LinksCacheTTL <= 0 {
+		finalConfig.LinksCacheTTL = defaultLinksCacheTTL
+	}
 
 	return &finalConfig, nil
 }
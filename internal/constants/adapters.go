@@ -1,6 +1,9 @@
 package constants
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 // Storage file parameters when using the 'In Memory' database.
 const (
@@ -15,3 +18,19 @@ const (
 	// Combination of O_RDONLY (read-only mode) and O_CREATE (create if not exists).
 	DefaultConsumerFileFlags = os.O_RDONLY | os.O_CREATE
 )
+
+// Schema migration parameters when using the 'PostgreSQL' database.
+const (
+	// MigrationsTimeout limits how long pending migrations may run while the application starts.
+	MigrationsTimeout = time.Minute
+)
+
+// Parameters of the redirect cache in front of the 'PostgreSQL' database.
+const (
+	// LinksCacheNegativeTTL specifies how long unknown, deleted and expired links are remembered as such.
+	LinksCacheNegativeTTL = 10 * time.Second
+
+	// LinksCacheStaleTime specifies how long after going stale a cached link may still be served
+	// while the database cannot be reached.
+	LinksCacheStaleTime = 15 * time.Minute
+)
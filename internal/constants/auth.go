@@ -9,12 +9,35 @@ type userIDKey string
 // Used to set the expiration period for authentication tokens.
 const TokenExp = time.Hour * 3
 
-// CookieMaxAge sets the maximum lifetime of cookies (3600 seconds), which equals one hour.
-const CookieMaxAge = 3600
+// CookieMaxAge sets the maximum lifetime of access token cookies in seconds, matching the token expiration.
+const CookieMaxAge = int(TokenExp / time.Second)
 
-// JwtSecret holds the secret key used for signing JWT tokens.
-// It is important to keep this key secure and avoid exposing it.
-const JwtSecret = "your_secret_key"
+// TokenRenewBefore sets how long before its expiry an access token is reissued on use.
+const TokenRenewBefore = time.Hour
+
+// RefreshTokenExp defines how long a refresh token stays valid after its last use (30 days).
+const RefreshTokenExp = 30 * 24 * time.Hour
+
+// RefreshTokenLength sets the number of random base62 characters of a refresh token.
+const RefreshTokenLength = 48
+
+// Names of the cookies carrying the session tokens.
+const (
+	AccessTokenCookie  = "access_token"  // Short-lived signed JWT identifying the user.
+	RefreshTokenCookie = "refresh_token" // Long-lived opaque token used to reissue the access token.
+)
+
+// APIKeyPrefix marks API keys issued by the service, making them easy to recognize in secret scanners.
+const APIKeyPrefix = "shk_"
+
+// APIKeyLength sets the number of random base62 characters following the prefix of an API key.
+const APIKeyLength = 40
+
+// APIKeyVisibleLength sets how many leading characters of an API key are stored in clear to help recognize it.
+const APIKeyVisibleLength = 8
 
 // UserIDKey represents a unique identifier key for users stored in HTTP request contexts.
 const UserIDKey userIDKey = "UserID"
+
+// NewUserKey marks request contexts whose user was created anonymously by the request itself.
+const NewUserKey userIDKey = "NewUser"
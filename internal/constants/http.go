@@ -13,6 +13,12 @@ const (
 	// JSONContentType is the MIME type for JSON-formatted data.
 	JSONContentType = "application/json"
 
+	// CSVContentType is the MIME type for comma-separated values.
+	CSVContentType = "text/csv"
+
+	// JSONLinesContentType is the MIME type for newline-delimited JSON documents.
+	JSONLinesContentType = "application/x-ndjson"
+
 	// URLPrefix is the standard prefix for HTTP URLs.
 	URLPrefix = "http://"
 
@@ -25,3 +31,18 @@ const (
 	// KeyFile is the name of the private key file used for TLS/SSL.
 	KeyFile = "key.pem"
 )
+
+// Scopes and parameters of request rate limits.
+const (
+	// CreateRateLimitScope names the budget shared by the link creation routes.
+	CreateRateLimitScope = "create"
+
+	// RedirectRateLimitScope names the budget of redirects through short links.
+	RedirectRateLimitScope = "redirect"
+
+	// RateLimitsSweepInterval specifies how often idle rate limit buckets are removed from storage.
+	RateLimitsSweepInterval = time.Minute
+
+	// RateLimitTimeout bounds the time spent taking a rate limit token, after which the request is let through.
+	RateLimitTimeout = 100 * time.Millisecond
+)
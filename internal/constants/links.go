@@ -0,0 +1,93 @@
+package constants
+
+import "time"
+
+// Parameters of the background sweeper for expired and deleted links.
+const (
+	// LinksSweepInterval specifies how often expired and deleted links are purged from storage.
+	LinksSweepInterval = time.Minute
+
+	// ExpiredLinksRetention specifies how long expired links are kept to answer 410 Gone before being purged.
+	ExpiredLinksRetention = 24 * time.Hour
+)
+
+// DomainPolicyReloadInterval specifies how often the domain policy file is checked for changes.
+const DomainPolicyReloadInterval = 5 * time.Second
+
+// Statuses of individual items in a batch shortening response.
+const (
+	// LinkCreated marks an item for which a new short link was created.
+	LinkCreated = "created"
+
+	// LinkExists marks an item whose origin had already been shortened; the existing short link is returned.
+	LinkExists = "exists"
+
+	// LinkInvalid marks an item that was rejected; the result holds the reason.
+	LinkInvalid = "invalid"
+
+	// LinkRenamed marks an imported item whose short code was already in use; a new short link was generated for it.
+	LinkRenamed = "renamed"
+
+	// LinkTaken marks an imported item whose short code is already in use; it is not added under that code.
+	LinkTaken = "taken"
+)
+
+// Parameters of bulk link imports.
+const (
+	// ImportBatchSize specifies how many imported rows are stored in a single write.
+	ImportBatchSize = 500
+
+	// ImportMaxLineSize specifies the longest line accepted in a JSON Lines import.
+	ImportMaxLineSize = 64 * 1024
+
+	// ImportAborted marks the last line of an import report when the rest of the input could not be imported.
+	ImportAborted = "aborted"
+)
+
+// Sort keys and limits of user link listings.
+const (
+	// LinksSortCreated orders user links by creation time.
+	LinksSortCreated = "created"
+
+	// LinksSortClicks orders user links by the number of redirects through them.
+	LinksSortClicks = "clicks"
+
+	// MaxLinksPageSize specifies the largest page of user links that may be requested.
+	MaxLinksPageSize = 1000
+)
+
+// Parameters of the background link deletion queue.
+const (
+	// DeletionQueueSize specifies how many links may wait for deletion before new requests are rejected.
+	DeletionQueueSize = 16384
+
+	// DeletionWorkers specifies how many workers apply queued deletions concurrently.
+	DeletionWorkers = 4
+
+	// DeletionBatchSize specifies the maximum number of links deleted in a single write.
+	DeletionBatchSize = 500
+
+	// DeletionFlushInterval specifies how often queued deletions are applied even if the batch is not full.
+	DeletionFlushInterval = 100 * time.Millisecond
+
+	// DeletionMaxAttempts specifies how many times a failed batch of deletions is attempted before it is dropped.
+	DeletionMaxAttempts = 3
+
+	// DeletionRetryDelay specifies the base delay between attempts, growing linearly with each failed attempt.
+	DeletionRetryDelay = 200 * time.Millisecond
+)
+
+// Parameters of the asynchronous click recorder.
+const (
+	// ClicksQueueSize specifies how many clicks may wait for storage before new ones are dropped.
+	ClicksQueueSize = 4096
+
+	// ClicksBatchSize specifies the maximum number of clicks stored in a single write.
+	ClicksBatchSize = 256
+
+	// ClicksFlushInterval specifies how often queued clicks are stored even if the batch is not full.
+	ClicksFlushInterval = time.Second
+
+	// IPHashSecretSize specifies the size in bytes of the random secret keying client IP hashes when none is configured.
+	IPHashSecretSize = 32
+)
@@ -7,16 +7,31 @@ type HealthHandlers interface {
 	Ping(w http.ResponseWriter, r *http.Request) // Handles health check requests.
 }
 
-// LinkHandlers aggregates handlers dealing with link manipulation (creation, retrieval).
+// LinkHandlers aggregates handlers dealing with link manipulation (creation, retrieval, editing).
 type LinkHandlers interface {
 	AddLinkInText(w http.ResponseWriter, r *http.Request) // Adds a link embedded in HTML body.
 	AddLink(w http.ResponseWriter, r *http.Request)       // Adds a link extracted from the request payload.
 	AddLinks(w http.ResponseWriter, r *http.Request)      // Batches addition of multiple links.
 	GetLink(w http.ResponseWriter, r *http.Request)       // Retrieves a previously-shortened link.
+	UpdateLink(w http.ResponseWriter, r *http.Request)    // Changes the destination of a user's link.
+	ImportLinks(w http.ResponseWriter, r *http.Request)   // Imports links migrated from another shortener.
+}
+
+// ClicksHandlers groups handlers exposing click analytics.
+type ClicksHandlers interface {
+	GetStats(w http.ResponseWriter, r *http.Request) // Reports click statistics of a user's link.
 }
 
 // UsersHandlers collects handlers focused on user-specific actions like fetching/deleting links.
 type UsersHandlers interface {
-	GetLinks(w http.ResponseWriter, r *http.Request)    // Fetches all links owned by the authenticated user.
-	DeleteLinks(w http.ResponseWriter, r *http.Request) // Deletes selected links belonging to the user.
+	GetLinks(w http.ResponseWriter, r *http.Request)     // Fetches all links owned by the authenticated user.
+	ExportLinks(w http.ResponseWriter, r *http.Request)  // Streams all links of the user as CSV, JSON or JSON Lines.
+	DeleteLinks(w http.ResponseWriter, r *http.Request)  // Deletes selected links belonging to the user.
+	RestoreLinks(w http.ResponseWriter, r *http.Request) // Restores soft-deleted links belonging to the user.
+	CreateAPIKey(w http.ResponseWriter, r *http.Request) // Issues a new API key to the user.
+	GetAPIKeys(w http.ResponseWriter, r *http.Request)   // Lists the user's API keys.
+	RevokeAPIKey(w http.ResponseWriter, r *http.Request) // Revokes one of the user's API keys.
+	Logout(w http.ResponseWriter, r *http.Request)       // Ends the user's session.
+	Register(w http.ResponseWriter, r *http.Request)     // Registers an account with a username and password.
+	Login(w http.ResponseWriter, r *http.Request)        // Signs in to a registered account.
 }
@@ -3,6 +3,7 @@ package interfaces
 import (
 	"context"
 	"main/internal/models"
+	"time"
 )
 
 // HealthRepository outlines methods for checking system health and readiness.
@@ -14,7 +15,23 @@ type HealthRepository interface {
 type LinksRepository interface {
 	Add(ctx context.Context, addedLink models.AddedLink) (string, error)                  // Adds a single link.
 	AddBatch(ctx context.Context, addedLinks []models.AddedLink) ([]models.Result, error) // Adds multiple links in batch.
+	Import(ctx context.Context, addedLinks []models.AddedLink) ([]models.Result, error)   // Adds links with preset short codes, reporting taken codes per item.
 	Get(ctx context.Context, short string) (string, error)                                // Retrieves the original URL for a given short link.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)                   // Removes links expired before the given moment.
+	DeleteSoftDeleted(ctx context.Context, before time.Time) (int64, error)               // Permanently removes links soft-deleted before the given moment.
+	Update(ctx context.Context, short, origin string) error                               // Changes the destination of a link owned by the user.
+}
+
+// RateLimitRepository keeps the token buckets of rate limits.
+type RateLimitRepository interface {
+	Take(ctx context.Context, key string, limit models.RateLimit, now time.Time) (models.RateLimitBucket, error) // Refills the bucket of the key and takes a token from it if there is one.
+	DeleteIdle(ctx context.Context, before time.Time) (int64, error)                                             // Removes buckets last used before the given moment.
+}
+
+// ClicksRepository stores redirect events and aggregates them into statistics.
+type ClicksRepository interface {
+	AddBatch(ctx context.Context, clicks []models.Click) error            // Stores multiple clicks at once.
+	GetStats(ctx context.Context, short string) (models.LinkStats, error) // Aggregates clicks of a short link owned by the user.
 }
 
 // FileStorageProducer abstracts the process of writing events to a persistent storage medium.
@@ -31,7 +48,20 @@ type FileStorageConsumer interface {
 
 // UsersRepository handles user-specific operations such as logging in, fetching links, and deleting links.
 type UsersRepository interface {
-	Login(ctx context.Context) (int64, error)                   // Logs in a user and assigns a unique identifier.
-	GetLinks(ctx context.Context) ([]models.UserLinks, error)   // Retrieves all links created by the user.
-	DeleteLinks(ctx context.Context, shortLinks []string) error // Removes specified links created by the user.
+	Login(ctx context.Context) (int64, error)                                                // Logs in a user and assigns a unique identifier.
+	GetLinks(ctx context.Context, query models.LinksQuery) ([]models.UserLinks, error)       // Retrieves the user's links matching the query.
+	ExportLinks(ctx context.Context, yield func(models.ExportedLink) error) error            // Streams all links of the user, including deleted ones, one at a time.
+	DeleteLinks(ctx context.Context, deletions []models.LinkDeletion) error                  // Soft-deletes links of possibly different users, skipping links not owned by the requester.
+	RestoreLinks(ctx context.Context, shortLinks []string) (int64, error)                    // Undoes the soft-deletion of links created by the user.
+	AddAPIKey(ctx context.Context, key models.APIKey) error                                  // Stores a new API key of the user.
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)                                 // Retrieves all API keys of the user.
+	DeleteAPIKey(ctx context.Context, id string) error                                       // Revokes an API key of the user.
+	GetUserByAPIKey(ctx context.Context, hash string) (int64, error)                         // Resolves the owner of an API key by its hash.
+	AddRefreshToken(ctx context.Context, token models.RefreshToken) error                    // Stores a new refresh token.
+	ExtendRefreshToken(ctx context.Context, hash string, expiresAt time.Time) (int64, error) // Extends an unexpired refresh token and returns its owner.
+	DeleteRefreshToken(ctx context.Context, hash string) error                               // Revokes a refresh token.
+	GetUser(ctx context.Context, userID int64) (models.User, error)                          // Retrieves a user by ID.
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)             // Retrieves a registered user by username.
+	SetCredentials(ctx context.Context, userID int64, username, passwordHash string) error   // Turns an anonymous user into a registered one.
+	ClaimLinks(ctx context.Context, fromUserID, toUserID int64) (int64, error)               // Moves links of an anonymous user to another user.
 }
@@ -14,12 +14,51 @@ type HealthService interface {
 type LinksService interface {
 	Add(ctx context.Context, originLink models.OriginLink, host string) (string, error)                  // Adds a single link.
 	AddBatch(ctx context.Context, originLinks []models.OriginLink, host string) ([]models.Result, error) // Batch-adds multiple links.
+	Import(ctx context.Context, originLinks []models.OriginLink, host string) ([]models.Result, error)   // Imports links, preserving their short codes when free.
 	Get(ctx context.Context, shortLink string) (string, error)                                           // Retrieves the original URL for a given short link.
+	PurgeExpired(ctx context.Context) (int64, error)                                                     // Removes links whose retention after expiry has passed.
+	PurgeDeleted(ctx context.Context) (int64, error)                                                     // Permanently removes links soft-deleted longer than the retention period.
+	Update(ctx context.Context, shortLink, originLink string) error                                      // Changes the destination of a link owned by the user.
+}
+
+// ClicksService records redirects asynchronously and reports click statistics.
+type ClicksService interface {
+	Record(short, referrer, userAgent, clientIP string)                                // Queues a click for asynchronous storage.
+	Run(ctx context.Context)                                                           // Stores queued clicks until the context is canceled.
+	GetStats(ctx context.Context, short string, host string) (models.LinkStats, error) // Retrieves click statistics of a user's link.
 }
 
 // UsersService manages user-specific activities such as login, link retrieval, and deletion.
 type UsersService interface {
-	Login() (int64, error)                                                 // Logs in a user and generates a unique identifier.
-	GetLinks(ctx context.Context, host string) ([]models.UserLinks, error) // Retrieves all links created by the logged-in user.
-	DeleteLinks(ctx context.Context, shortLinks []string) error            // Deletes specified links created by the user.
+	Login() (int64, error)                                                                                                 // Logs in a user and generates a unique identifier.
+	GetLinks(ctx context.Context, host string, query models.LinksQuery, cursor string) ([]models.UserLinks, string, error) // Retrieves a page of the logged-in user's links and the cursor of the next page.
+	ExportLinks(ctx context.Context, host string, yield func(models.ExportedLink) error) error                             // Streams all links of the logged-in user one at a time.
+	DeleteLinks(ctx context.Context, shortLinks []string) error                                                            // Queues specified links created by the user for deletion.
+	RestoreLinks(ctx context.Context, shortLinks []string) (int64, error)                                                  // Restores soft-deleted links of the user.
+	CreateAPIKey(ctx context.Context, name string) (models.APIKey, string, error)                                          // Issues a new API key, returning the plain key once.
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)                                                               // Lists the API keys of the logged-in user.
+	RevokeAPIKey(ctx context.Context, id string) error                                                                     // Revokes an API key of the logged-in user.
+	AuthenticateAPIKey(ctx context.Context, key string) (int64, error)                                                     // Resolves the user owning a plain API key.
+	IssueRefreshToken(ctx context.Context, userID int64) (string, error)                                                   // Starts a server-side session for the user.
+	RefreshSession(ctx context.Context, token string) (int64, error)                                                       // Extends a session and returns its user.
+	Logout(ctx context.Context, token string) error                                                                        // Revokes a session.
+	Register(ctx context.Context, username, password string) (int64, error)                                                // Registers an account, keeping the links of the current anonymous user.
+	Authenticate(ctx context.Context, username, password string) (int64, error)                                            // Signs in to an account, claiming the links of the current anonymous user.
+}
+
+// LinksDeleter applies link deletions asynchronously in the background.
+type LinksDeleter interface {
+	Enqueue(userID int64, shortLinks []string) error // Queues links of a user for deletion without waiting for the result.
+	Run(ctx context.Context)                         // Applies queued deletions until the context is canceled, then drains the queue.
+}
+
+// DomainPolicy decides which domains links may point to.
+type DomainPolicy interface {
+	Check(rawURL string) error // Returns an error if the domain of the URL is not allowed.
+	Reload() (bool, error)     // Reloads the rules if their source has changed, reporting whether they did.
+}
+
+// ShortCodeGenerator produces candidate short codes for new links.
+type ShortCodeGenerator interface {
+	Generate() (string, error) // Returns a new short code candidate.
 }
@@ -8,57 +8,227 @@ import (
 	"main/internal/constants"
 	"main/internal/interfaces"
 	"main/internal/models"
+	"main/internal/services"
 	"net/http"
+	"strings"
 	"time"
 )
 
 // UserService users service for authentication.
 var UserService interfaces.UsersService
 
-// Authentication wraps the next handler with JWT-based authentication.
-func Authentication(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if UserService != nil {
-			cookie, err := r.Cookie("access_token")
-			if err != nil || cookie == nil {
-				userID, err := UserService.Login()
-				if err != nil {
-					http.Error(w, err.Error(), http.StatusInternalServerError)
+// Authentication policies deciding how requests are identified.
+const (
+	PublicPolicy   = "public"   // No identity is resolved; used for anonymous routes such as redirects.
+	OptionalPolicy = "optional" // An existing valid identity is used if there is one, but none is created or required.
+	LazyPolicy     = "lazy"     // An existing identity is used; an anonymous user is created if there is none.
+	RequiredPolicy = "required" // An existing identity is required, otherwise the request is rejected.
+)
+
+// ErrInvalidPolicy is returned when a policy cannot be used for routes that need a user.
+var ErrInvalidPolicy = errors.New("invalid authentication policy")
+
+// ValidateUserPolicy checks that a policy identifies the user, as required by routes creating links.
+func ValidateUserPolicy(policy string) error {
+	if policy != LazyPolicy && policy != RequiredPolicy {
+		return fmt.Errorf("%w: %q, expected %q or %q", ErrInvalidPolicy, policy, LazyPolicy, RequiredPolicy)
+	}
+	return nil
+}
+
+// Authentication returns middleware identifying the user of a request according to the given policy.
+// The identity comes from an "Authorization: Bearer <key>" API key header or, failing that, the access_token cookie.
+// Requests with an invalid key or token are rejected regardless of the policy, except for public routes.
+func Authentication(policy string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if policy == PublicPolicy {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if UserService == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, found, err := identify(w, r)
+			if policy == OptionalPolicy {
+				if err == nil && found {
+					r = r.WithContext(context.WithValue(r.Context(), constants.UserIDKey, userID))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			if errors.Is(err, errIdentityUnavailable) {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if err != nil {
+				unauthorized(w, r)
+				return
+			}
+			if !found {
+				if policy != LazyPolicy {
+					unauthorized(w, r)
+					return
+				}
+				if !allowNewUser(w, r) {
 					return
 				}
-				cookie, err = setJWTCookie(userID)
+				userID, err = UserService.Login()
 				if err != nil {
 					http.Error(w, err.Error(), http.StatusInternalServerError)
 					return
 				}
-				http.SetCookie(w, cookie)
-				ctx := context.WithValue(r.Context(), constants.UserIDKey, userID)
-				next.ServeHTTP(w, r.WithContext(ctx))
-			} else {
-				tokenStr := cookie.Value
-				claims, err := verifyJWT(tokenStr)
-				if err != nil {
-					w.Header().Set("content-type", constants.TextContentType)
-					w.WriteHeader(http.StatusUnauthorized)
+				if err := startSession(w, r, userID); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
 					return
 				}
-				ctx := context.WithValue(r.Context(), constants.UserIDKey, claims.UserID)
-				next.ServeHTTP(w, r.WithContext(ctx))
+				r = r.WithContext(context.WithValue(r.Context(), constants.NewUserKey, true))
 			}
-		} else {
-			next.ServeHTTP(w, r)
+
+			ctx := context.WithValue(r.Context(), constants.UserIDKey, userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// errIdentityUnavailable marks failures to resolve the identity of a request that are not caused by its credentials,
+// such as an unreachable repository.
+var errIdentityUnavailable = errors.New("identity unavailable")
+
+// identify resolves the user of a request from its API key, access token or refresh token.
+// Access tokens close to expiry are reissued along with the session, and expired or missing ones are restored
+// from the refresh token.
+// It reports whether the request carries any identity at all; errors not caused by the credentials
+// wrap errIdentityUnavailable.
+func identify(w http.ResponseWriter, r *http.Request) (int64, bool, error) {
+	if key, ok := bearerToken(r); ok {
+		userID, err := UserService.AuthenticateAPIKey(r.Context(), key)
+		if err != nil && !errors.Is(err, services.ErrInvalidAPIKey) {
+			return 0, true, fmt.Errorf("%w: %w", errIdentityUnavailable, err)
 		}
-	})
+		return userID, true, err
+	}
+
+	if cookie, err := r.Cookie(constants.AccessTokenCookie); err == nil {
+		claims, err := verifyJWT(cookie.Value)
+		if err == nil {
+			if claims.ExpiresAt != nil && time.Until(claims.ExpiresAt.Time) < constants.TokenRenewBefore {
+				if err := renewSession(w, r, claims.UserID); err != nil {
+					return 0, true, fmt.Errorf("%w: %w", errIdentityUnavailable, err)
+				}
+			}
+			return claims.UserID, true, nil
+		}
+		if !errors.Is(err, jwt.ErrTokenExpired) {
+			return 0, true, err
+		}
+	}
+
+	cookie, err := r.Cookie(constants.RefreshTokenCookie)
+	if err != nil {
+		return 0, false, nil
+	}
+	userID, err := UserService.RefreshSession(r.Context(), cookie.Value)
+	if errors.Is(err, services.ErrSessionNotFound) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, true, fmt.Errorf("%w: %w", errIdentityUnavailable, err)
+	}
+	if err := renewAccessToken(w, userID); err != nil {
+		return 0, true, err
+	}
+	http.SetCookie(w, refreshTokenCookie(cookie.Value))
+	return userID, true, nil
 }
 
-// verifyJWT validates a JWT token and extracts the user ID claim.
-func verifyJWT(tokenStr string) (*models.Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &models.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// StartSession switches the client to the session of the given user, e.g. after signing in.
+// The refresh token the request carries, if any, is revoked first.
+func StartSession(w http.ResponseWriter, r *http.Request, userID int64) error {
+	if cookie, err := r.Cookie(constants.RefreshTokenCookie); err == nil {
+		if err := UserService.Logout(r.Context(), cookie.Value); err != nil {
+			return err
 		}
-		return []byte(constants.JwtSecret), nil
-	})
+	}
+	return startSession(w, r, userID)
+}
+
+// startSession issues the access and refresh token cookies for a newly created user.
+func startSession(w http.ResponseWriter, r *http.Request, userID int64) error {
+	refreshToken, err := UserService.IssueRefreshToken(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+	if err := renewAccessToken(w, userID); err != nil {
+		return err
+	}
+	http.SetCookie(w, refreshTokenCookie(refreshToken))
+	return nil
+}
+
+// renewSession reissues the access token of a user and extends the session of the refresh token the request
+// carries, so that sessions in use do not expire. Clients without a refresh token of the user get a new one.
+func renewSession(w http.ResponseWriter, r *http.Request, userID int64) error {
+	cookie, err := r.Cookie(constants.RefreshTokenCookie)
+	if err != nil {
+		return startSession(w, r, userID)
+	}
+	tokenUserID, err := UserService.RefreshSession(r.Context(), cookie.Value)
+	if errors.Is(err, services.ErrSessionNotFound) || (err == nil && tokenUserID != userID) {
+		return startSession(w, r, userID)
+	} else if err != nil {
+		return err
+	}
+	if err := renewAccessToken(w, userID); err != nil {
+		return err
+	}
+	http.SetCookie(w, refreshTokenCookie(cookie.Value))
+	return nil
+}
+
+// renewAccessToken sets a freshly issued access token cookie.
+func renewAccessToken(w http.ResponseWriter, userID int64) error {
+	cookie, err := setJWTCookie(userID)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, cookie)
+	return nil
+}
+
+// refreshTokenCookie packages a refresh token into an HTTP cookie living as long as the session.
+func refreshTokenCookie(token string) *http.Cookie {
+	return &http.Cookie{
+		Name:     constants.RefreshTokenCookie,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(constants.RefreshTokenExp / time.Second),
+	}
+}
+
+// unauthorized rejects a request lacking a valid identity.
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	if _, ok := bearerToken(r); ok {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// verifyJWT validates a JWT token against the configured key set and extracts the user ID claim.
+func verifyJWT(tokenStr string) (*models.Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &models.Claims{}, Keys.Keyfunc)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +246,7 @@ func setJWTCookie(userID int64) (*http.Cookie, error) {
 		return nil, err
 	}
 	cookie := http.Cookie{
-		Name:     "access_token",
+		Name:     constants.AccessTokenCookie,
 		Value:    tokenStr,
 		Path:     "/",
 		HttpOnly: true,
@@ -85,7 +255,7 @@ func setJWTCookie(userID int64) (*http.Cookie, error) {
 	return &cookie, nil
 }
 
-// generateJWT issues a signed JWT token with a specified user ID and expiry.
+// generateJWT issues a JWT token with a specified user ID and expiry, signed by the active key.
 func generateJWT(userID int64) (string, error) {
 	expirationTime := time.Now().Add(constants.TokenExp)
 	claims := &models.Claims{
@@ -94,10 +264,8 @@ func generateJWT(userID int64) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(constants.JwtSecret))
+	tokenString, err := Keys.Sign(claims)
 	if err != nil {
-		fmt.Println(err)
 		return "", err
 	}
 
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/constants"
+	"main/internal/mocks"
+	"main/internal/models"
+	"main/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticationPolicies(t *testing.T) {
+	var err error
+	Keys, err = NewKeySet("secret", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		policy     string
+		cookie     bool
+		refresh    bool
+		refreshErr error
+		logins     int
+		wantStatus int
+		wantUser   bool
+	}{
+		{name: "public without cookie", policy: PublicPolicy, wantStatus: http.StatusOK},
+		{name: "lazy without cookie", policy: LazyPolicy, logins: 1, wantStatus: http.StatusOK, wantUser: true},
+		{name: "lazy with cookie", policy: LazyPolicy, cookie: true, wantStatus: http.StatusOK, wantUser: true},
+		{name: "required without cookie", policy: RequiredPolicy, wantStatus: http.StatusUnauthorized},
+		{name: "required with cookie", policy: RequiredPolicy, cookie: true, wantStatus: http.StatusOK, wantUser: true},
+		{name: "required with refresh token", policy: RequiredPolicy, refresh: true, wantStatus: http.StatusOK, wantUser: true},
+		{name: "required with revoked refresh token", policy: RequiredPolicy, refresh: true, refreshErr: services.ErrSessionNotFound, wantStatus: http.StatusUnauthorized},
+		{name: "optional without cookie", policy: OptionalPolicy, wantStatus: http.StatusOK},
+		{name: "optional with cookie", policy: OptionalPolicy, cookie: true, wantStatus: http.StatusOK, wantUser: true},
+		{name: "optional with revoked refresh token", policy: OptionalPolicy, refresh: true, refreshErr: services.ErrSessionNotFound, wantStatus: http.StatusOK},
+		{name: "lazy with revoked refresh token", policy: LazyPolicy, refresh: true, refreshErr: services.ErrSessionNotFound, logins: 1, wantStatus: http.StatusOK, wantUser: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			usersService := mocks.NewMockUsersService(ctrl)
+			usersService.EXPECT().Login().Return(int64(7), nil).Times(test.logins)
+			usersService.EXPECT().IssueRefreshToken(gomock.Any(), int64(7)).Return("refresh", nil).Times(test.logins)
+			UserService = usersService
+			defer func() { UserService = nil }()
+
+			var gotUser bool
+			handler := Authentication(test.policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, gotUser = r.Context().Value(constants.UserIDKey).(int64)
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.cookie {
+				cookie, err := setJWTCookie(7)
+				require.NoError(t, err)
+				request.AddCookie(cookie)
+			}
+			if test.refresh {
+				usersService.EXPECT().RefreshSession(gomock.Any(), "refresh").Return(int64(7), test.refreshErr)
+				request.AddCookie(refreshTokenCookie("refresh"))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, request)
+
+			assert.Equal(t, test.wantStatus, w.Code)
+			assert.Equal(t, test.wantUser, gotUser)
+		})
+	}
+}
+
+func TestAuthenticationAPIKeyErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "valid key", wantStatus: http.StatusOK},
+		{name: "invalid key", err: services.ErrInvalidAPIKey, wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", err: fmt.Errorf("%w: %w", services.ErrInvalidAPIKey, services.ErrAPIKeyNotFound), wantStatus: http.StatusUnauthorized},
+		{name: "repository failure", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			usersService := mocks.NewMockUsersService(ctrl)
+			usersService.EXPECT().AuthenticateAPIKey(gomock.Any(), "key").Return(int64(7), test.err)
+			UserService = usersService
+			defer func() { UserService = nil }()
+
+			handler := Authentication(RequiredPolicy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Authorization", "Bearer key")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, request)
+
+			assert.Equal(t, test.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestAuthenticationSlidingSession(t *testing.T) {
+	var err error
+	Keys, err = NewKeySet("secret", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		expiresIn   time.Duration
+		refresh     bool
+		refreshErr  error
+		issued      int
+		wantStatus  int
+		wantRefresh string
+	}{
+		{name: "fresh access token", expiresIn: constants.TokenExp, refresh: true, wantStatus: http.StatusOK},
+		{name: "renewal extends the session", expiresIn: time.Minute, refresh: true, wantStatus: http.StatusOK, wantRefresh: "refresh"},
+		{name: "renewal without refresh token", expiresIn: time.Minute, issued: 1, wantStatus: http.StatusOK, wantRefresh: "issued"},
+		{name: "renewal with revoked refresh token", expiresIn: time.Minute, refresh: true, refreshErr: services.ErrSessionNotFound, issued: 1, wantStatus: http.StatusOK, wantRefresh: "issued"},
+		{name: "renewal with failing store", expiresIn: time.Minute, refresh: true, refreshErr: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			usersService := mocks.NewMockUsersService(ctrl)
+			if test.refresh && test.expiresIn < constants.TokenRenewBefore {
+				usersService.EXPECT().RefreshSession(gomock.Any(), "refresh").Return(int64(7), test.refreshErr)
+			}
+			usersService.EXPECT().IssueRefreshToken(gomock.Any(), int64(7)).Return("issued", nil).Times(test.issued)
+			UserService = usersService
+			defer func() { UserService = nil }()
+
+			token, err := Keys.Sign(&models.Claims{
+				UserID:           7,
+				RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(test.expiresIn))},
+			})
+			require.NoError(t, err)
+
+			handler := Authentication(RequiredPolicy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.AddCookie(&http.Cookie{Name: constants.AccessTokenCookie, Value: token})
+			if test.refresh {
+				request.AddCookie(refreshTokenCookie("refresh"))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, request)
+
+			assert.Equal(t, test.wantStatus, w.Code)
+			cookies := make(map[string]*http.Cookie)
+			for _, cookie := range w.Result().Cookies() {
+				cookies[cookie.Name] = cookie
+			}
+			if test.wantRefresh == "" {
+				assert.NotContains(t, cookies, constants.RefreshTokenCookie)
+				return
+			}
+			require.Contains(t, cookies, constants.RefreshTokenCookie)
+			assert.Equal(t, test.wantRefresh, cookies[constants.RefreshTokenCookie].Value)
+			assert.Equal(t, int(constants.RefreshTokenExp/time.Second), cookies[constants.RefreshTokenCookie].MaxAge)
+			assert.Contains(t, cookies, constants.AccessTokenCookie, "the access token is reissued")
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8, 192.0.2.1")
+	require.NoError(t, err)
+	TrustedProxies = proxies
+	defer func() { TrustedProxies = nil }()
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{name: "direct", remoteAddr: "203.0.113.7:1234", want: "203.0.113.7"},
+		{name: "spoofed by a direct client", remoteAddr: "203.0.113.7:1234", forwarded: []string{"198.51.100.1"}, realIP: "198.51.100.2", want: "203.0.113.7"},
+		{name: "through a trusted proxy", remoteAddr: "10.1.2.3:1234", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed through a trusted proxy", remoteAddr: "10.1.2.3:1234", forwarded: []string{"1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "through a chain of trusted proxies", remoteAddr: "10.1.2.3:1234", forwarded: []string{"1.2.3.4, 198.51.100.1", "192.0.2.1, 10.9.9.9"}, want: "198.51.100.1"},
+		{name: "only trusted hops", remoteAddr: "10.1.2.3:1234", forwarded: []string{"10.0.0.1, 10.0.0.2"}, want: "10.0.0.1"},
+		{name: "real ip from a trusted proxy", remoteAddr: "192.0.2.1:1234", realIP: "198.51.100.3", want: "198.51.100.3"},
+		{name: "trusted proxy without headers", remoteAddr: "192.0.2.1:1234", want: "192.0.2.1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.RemoteAddr = test.remoteAddr
+			for _, value := range test.forwarded {
+				request.Header.Add("X-Forwarded-For", value)
+			}
+			if test.realIP != "" {
+				request.Header.Set("X-Real-IP", test.realIP)
+			}
+			assert.Equal(t, test.want, ClientIP(request))
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies("")
+	require.NoError(t, err)
+	assert.Empty(t, proxies)
+
+	proxies, err = ParseTrustedProxies("127.0.0.1, ::1, 10.1.2.3/8")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1/32", "::1/128", "10.0.0.0/8"}, []string{proxies[0].String(), proxies[1].String(), proxies[2].String()})
+
+	_, err = ParseTrustedProxies("localhost")
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies("10.0.0.0/33")
+	assert.Error(t, err)
+}
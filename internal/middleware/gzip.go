@@ -2,38 +2,95 @@ package middleware
 
 import (
 	"compress/gzip"
-	"io"
+	"mime"
 	"net/http"
 	"strings"
 )
 
-// Available content types that support gzip encoding.
+// Available content subtypes that support gzip encoding.
 var availableContentTypes = map[string]bool{
-	"json": true,
-	"html": true,
+	"json":     true,
+	"html":     true,
+	"csv":      true,
+	"x-ndjson": true,
 }
 
-// gzipWriter adapts ResponseWriter to work with compressed output streams.
+// gzipWriter adapts ResponseWriter to compress the response body once its content type turns out to support it.
 type gzipWriter struct {
-	http.ResponseWriter           // Wraps the original response writer.
-	Writer              io.Writer // Compressed stream writer.
+	http.ResponseWriter              // Wraps the original response writer.
+	writer              *gzip.Writer // Compressed stream writer, nil if the response is not compressed.
+	started             bool         // Indicates whether the response header has been written.
 }
 
-// Write overrides the Write method to delegate to the wrapped writer.
-func (w gzipWriter) Write(b []byte) (int, error) {
-	return w.Writer.Write(b)
+// WriteHeader decides whether to compress the response by its content type and writes the header.
+func (w *gzipWriter) WriteHeader(statusCode int) {
+	if !w.started {
+		w.started = true
+		if compressible(w.Header(), statusCode) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Del("Content-Length")
+			w.writer, _ = gzip.NewWriterLevel(w.ResponseWriter, gzip.BestSpeed)
+		}
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the body through the compressed stream if the response is compressed.
+func (w *gzipWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.writer != nil {
+		return w.writer.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends the data compressed so far to the client, so streamed responses stay streamed.
+func (w *gzipWriter) Flush() {
+	if w.writer != nil {
+		w.writer.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the original response writer.
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
 
-// GZipper compresses outgoing HTTP responses if the client accepts gzip encoding.
+// Close finishes the compressed stream, if any.
+func (w *gzipWriter) Close() error {
+	if w.writer != nil {
+		return w.writer.Close()
+	}
+	return nil
+}
+
+// compressible reports whether a response with the given header and status has a body worth compressing.
+func compressible(header http.Header, statusCode int) bool {
+	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified || header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	_, subtype, _ := strings.Cut(mediaType, "/")
+	return availableContentTypes[subtype]
+}
+
+// GZipper decompresses gzip-encoded request bodies and compresses outgoing HTTP responses
+// of supported content types if the client accepts gzip encoding.
 func GZipper(h http.Handler) http.Handler {
 	zipFn := func(w http.ResponseWriter, r *http.Request) {
 		acceptEncoding := r.Header.Get("Accept-Encoding")
 		contentEncoding := r.Header.Get("Content-Encoding")
 		supportsGzip := strings.Contains(acceptEncoding, "gzip")
 		sendsGzip := strings.Contains(contentEncoding, "gzip")
-		requestContentType := r.Header.Get("Content-Type")
-
-		zipped := availableContentTypes[requestContentType]
 
 		if sendsGzip {
 			gz, err := gzip.NewReader(r.Body)
@@ -44,15 +101,11 @@ func GZipper(h http.Handler) http.Handler {
 			r.Body = gz
 			defer gz.Close()
 		}
-		if supportsGzip && zipped {
-			gz, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
-			if err != nil {
-				io.WriteString(w, err.Error())
-				return
-			}
-			defer gz.Close()
-			w.Header().Set("Content-Encoding", "gzip")
-			h.ServeHTTP(gzipWriter{ResponseWriter: w, Writer: gz}, r)
+		if supportsGzip {
+			w.Header().Add("Vary", "Accept-Encoding")
+			gw := &gzipWriter{ResponseWriter: w}
+			defer gw.Close()
+			h.ServeHTTP(gw, r)
 		} else {
 			h.ServeHTTP(w, r)
 		}
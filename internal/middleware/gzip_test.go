@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"main/internal/constants"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGZipper(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		status         int
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{name: "json", contentType: constants.JSONContentType, status: http.StatusOK, acceptEncoding: "gzip", wantGzip: true},
+		{name: "csv", contentType: constants.CSVContentType, status: http.StatusOK, acceptEncoding: "gzip, deflate", wantGzip: true},
+		{name: "json lines", contentType: constants.JSONLinesContentType, status: http.StatusOK, acceptEncoding: "gzip", wantGzip: true},
+		{name: "plain text", contentType: constants.TextContentType, status: http.StatusOK, acceptEncoding: "gzip"},
+		{name: "not accepted", contentType: constants.JSONContentType, status: http.StatusOK},
+		{name: "no content", contentType: constants.JSONContentType, status: http.StatusNoContent, acceptEncoding: "gzip"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body := `{"short_url":"http://localhost/abc/"}`
+			if test.status == http.StatusNoContent {
+				body = ""
+			}
+			handler := GZipper(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("content-type", test.contentType)
+				w.WriteHeader(test.status)
+				io.WriteString(w, body)
+				http.NewResponseController(w).Flush()
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept-Encoding", test.acceptEncoding)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.status, res.StatusCode)
+			reader := io.Reader(res.Body)
+			if test.wantGzip {
+				assert.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+				gz, err := gzip.NewReader(res.Body)
+				require.NoError(t, err)
+				reader = gz
+			} else {
+				assert.Empty(t, res.Header.Get("Content-Encoding"))
+			}
+			got, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, body, string(got))
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"os"
+	"path/filepath"
+)
+
+// Names of the supported JWT signing algorithms.
+const (
+	HS256 = "HS256" // HMAC with SHA-256 using a shared secret.
+	RS256 = "RS256" // RSASSA-PKCS1-v1_5 with SHA-256 using an RSA key pair.
+	EdDSA = "EdDSA" // Ed25519 signatures.
+)
+
+// defaultKeyID identifies the key built from a plain shared secret.
+const defaultKeyID = "default"
+
+// ephemeralSecretSize is the size in bytes of the random secret used when no key is configured.
+const ephemeralSecretSize = 32
+
+// ErrUnknownKey is returned when a token refers to a key missing from the key set.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Keys holds the key set used for signing and verifying access tokens.
+var Keys *KeySet
+
+// KeySet is a collection of JWT keys identified by their kid.
+// Tokens are signed with the active key and verified with the key named in their kid header,
+// so retired keys can stay in the set until the tokens they signed expire.
+type KeySet struct {
+	active *signingKey            // Key used to sign new tokens.
+	keys   map[string]*signingKey // All known keys by their kid.
+}
+
+// signingKey pairs a JWT signing method with its key material.
+type signingKey struct {
+	kid    string            // Identifier put into the kid header of signed tokens.
+	method jwt.SigningMethod // Signing algorithm.
+	sign   any               // Secret or private key, nil for verification-only keys.
+	verify any               // Secret or public key.
+}
+
+// keysFile represents the structure of the JSON key set file, for example:
+//
+//	{
+//	  "active": "2026-10",
+//	  "keys": [
+//	    {"kid": "2026-10", "alg": "EdDSA", "private_key_file": "jwt-2026-10.pem"},
+//	    {"kid": "2026-07", "alg": "HS256", "secret": "previous secret"}
+//	  ]
+//	}
+type keysFile struct {
+	Active string      `json:"active"` // Kid of the key used to sign new tokens.
+	Keys   []keyConfig `json:"keys"`   // All keys accepted for verification.
+}
+
+// keyConfig describes a single key of the key set file.
+// Relative key file paths are resolved against the directory of the key set file.
+type keyConfig struct {
+	Kid            string `json:"kid"`                        // Key identifier.
+	Alg            string `json:"alg"`                        // Signing algorithm: HS256, RS256 or EdDSA.
+	Secret         string `json:"secret,omitempty"`           // Shared secret for HS256.
+	PrivateKeyFile string `json:"private_key_file,omitempty"` // PEM-encoded private key for RS256 and EdDSA.
+	PublicKeyFile  string `json:"public_key_file,omitempty"`  // PEM-encoded public key for verification-only keys.
+}
+
+// NewKeySet builds a key set from a key set file or, if no file is given, from a shared HS256 secret.
+// If neither is configured, a random secret is generated, so tokens do not survive a restart.
+func NewKeySet(secret string, keysFilePath string) (*KeySet, error) {
+	if keysFilePath != "" {
+		return loadKeySet(keysFilePath)
+	}
+	if secret == "" {
+		buf := make([]byte, ephemeralSecretSize)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+		}
+		secret = string(buf)
+	}
+	key := &signingKey{
+		kid:    defaultKeyID,
+		method: jwt.SigningMethodHS256,
+		sign:   []byte(secret),
+		verify: []byte(secret),
+	}
+	return &KeySet{
+		active: key,
+		keys:   map[string]*signingKey{key.kid: key},
+	}, nil
+}
+
+// Sign issues a token with the given claims signed by the active key.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.method, claims)
+	token.Header["kid"] = s.active.kid
+	return token.SignedString(s.active.sign)
+}
+
+// Keyfunc resolves the verification key of a token by its kid header,
+// rejecting tokens whose algorithm does not match the key.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verify, nil
+}
+
+// loadKeySet reads a key set from a JSON file.
+func loadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT keys file: %w", err)
+	}
+	var file keysFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse JWT keys file: %w", err)
+	}
+
+	set := &KeySet{
+		keys: make(map[string]*signingKey, len(file.Keys)),
+	}
+	for _, cfg := range file.Keys {
+		key, err := cfg.load(filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", cfg.Kid, err)
+		}
+		if _, ok := set.keys[key.kid]; ok {
+			return nil, fmt.Errorf("JWT key %q is defined twice", key.kid)
+		}
+		set.keys[key.kid] = key
+	}
+
+	active, ok := set.keys[file.Active]
+	if !ok {
+		return nil, fmt.Errorf("active JWT key %q is not defined", file.Active)
+	}
+	if active.sign == nil {
+		return nil, fmt.Errorf("active JWT key %q has no private key", file.Active)
+	}
+	set.active = active
+	return set, nil
+}
+
+// load parses the key material of a key set file entry.
+func (c keyConfig) load(dir string) (*signingKey, error) {
+	if c.Kid == "" {
+		return nil, errors.New("kid is required")
+	}
+	key := &signingKey{
+		kid: c.Kid,
+	}
+
+	switch c.Alg {
+	case HS256:
+		if c.Secret == "" {
+			return nil, errors.New("secret is required")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.sign, key.verify = []byte(c.Secret), []byte(c.Secret)
+	case RS256:
+		key.method = jwt.SigningMethodRS256
+		if c.PrivateKeyFile != "" {
+			data, err := readKeyFile(dir, c.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.sign, key.verify = private, &private.PublicKey
+		} else {
+			data, err := readKeyFile(dir, c.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			if key.verify, err = jwt.ParseRSAPublicKeyFromPEM(data); err != nil {
+				return nil, err
+			}
+		}
+	case EdDSA:
+		key.method = jwt.SigningMethodEdDSA
+		if c.PrivateKeyFile != "" {
+			data, err := readKeyFile(dir, c.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			private, err := jwt.ParseEdPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			edKey, ok := private.(ed25519.PrivateKey)
+			if !ok {
+				return nil, errors.New("private key is not an Ed25519 key")
+			}
+			key.sign, key.verify = edKey, edKey.Public()
+		} else {
+			data, err := readKeyFile(dir, c.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			if key.verify, err = jwt.ParseEdPublicKeyFromPEM(data); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", c.Alg)
+	}
+	return key, nil
+}
+
+// readKeyFile reads a PEM file, resolving relative paths against dir.
+func readKeyFile(dir, path string) ([]byte, error) {
+	if path == "" {
+		return nil, errors.New("private_key_file or public_key_file is required")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return os.ReadFile(path)
+}
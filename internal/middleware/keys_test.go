@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"main/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySetRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ed.pem"), pemData, 0600))
+
+	keysFile := filepath.Join(dir, "keys.json")
+	require.NoError(t, os.WriteFile(keysFile, []byte(`{
+		"active": "new",
+		"keys": [
+			{"kid": "new", "alg": "EdDSA", "private_key_file": "ed.pem"},
+			{"kid": "old", "alg": "HS256", "secret": "old secret"}
+		]
+	}`), 0600))
+
+	old, err := NewKeySet("old secret", "")
+	require.NoError(t, err)
+	old.active.kid = "old"
+	oldToken, err := old.Sign(&models.Claims{UserID: 1})
+	require.NoError(t, err)
+
+	set, err := NewKeySet("", keysFile)
+	require.NoError(t, err)
+	newToken, err := set.Sign(&models.Claims{UserID: 2})
+	require.NoError(t, err)
+
+	for userID, tokenStr := range map[int64]string{1: oldToken, 2: newToken} {
+		claims := &models.Claims{}
+		_, err := jwt.ParseWithClaims(tokenStr, claims, set.Keyfunc)
+		require.NoError(t, err)
+		assert.Equal(t, userID, claims.UserID)
+	}
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &models.Claims{UserID: 3})
+	forged.Header["kid"] = "new"
+	forgedStr, err := forged.SignedString([]byte("guess"))
+	require.NoError(t, err)
+	_, err = jwt.ParseWithClaims(forgedStr, &models.Claims{}, set.Keyfunc)
+	assert.Error(t, err)
+
+	unknown, err := NewKeySet("", "")
+	require.NoError(t, err)
+	unknownStr, err := unknown.Sign(&models.Claims{UserID: 4})
+	require.NoError(t, err)
+	_, err = jwt.ParseWithClaims(unknownStr, &models.Claims{}, set.Keyfunc)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
@@ -31,6 +31,11 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseData.status = statusCode
 }
 
+// Unwrap returns the original response writer, so streaming handlers can flush through the wrapper.
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // AccessLogger logs essential request and response metrics for every handled request.
 func AccessLogger(h http.Handler) http.Handler {
 	logFn := func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/adapters"
+	"main/internal/constants"
+	"main/internal/interfaces"
+	"main/internal/models"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimits stores the token buckets of the rate limiting middleware.
+var RateLimits interfaces.RateLimitRepository
+
+// NewUsersRateLimit limits per client IP address how many requests may create anonymous users.
+// It is taken from the budget of the link creation routes, the only ones creating anonymous users.
+var NewUsersRateLimit models.RateLimit
+
+// RateLimitOff disables a rate limit.
+const RateLimitOff = "off"
+
+// ErrInvalidRateLimit is returned when a rate limit cannot be parsed.
+var ErrInvalidRateLimit = errors.New("invalid rate limit")
+
+// ParseRateLimit parses a rate limit written as "<requests>/<period>", e.g. "100/1m" or "5/s".
+// The period is a duration whose count may be omitted; "off" disables the limit.
+func ParseRateLimit(spec string) (models.RateLimit, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == RateLimitOff {
+		return models.RateLimit{}, nil
+	}
+	requests, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return models.RateLimit{}, fmt.Errorf("%w: %q, expected <requests>/<period>", ErrInvalidRateLimit, spec)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return models.RateLimit{}, fmt.Errorf("%w: %q, requests must be a positive number", ErrInvalidRateLimit, spec)
+	}
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return models.RateLimit{}, fmt.Errorf("%w: %q, period must be a positive duration", ErrInvalidRateLimit, spec)
+	}
+	return models.RateLimit{Requests: n, Period: d}, nil
+}
+
+// RateLimit returns middleware limiting the requests of every client to the given token bucket budget.
+// Clients are identified by their user ID if the request has one, or by their IP address otherwise;
+// the scope keeps the budgets of different routes apart. Requests whose anonymous user was created by
+// Authentication have already been limited by their IP address under NewUsersRateLimit and are let through.
+// Responses carry the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers, and rejected
+// requests are answered with 429 Too Many Requests and a Retry-After header.
+// If the buckets cannot be reached, requests are let through rather than rejected.
+func RateLimit(scope string, limit models.RateLimit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit.Disabled() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if created, _ := r.Context().Value(constants.NewUserKey).(bool); created {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if takeRateLimit(w, r, rateLimitKey(scope, r), limit) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// allowNewUser takes a token of NewUsersRateLimit for the client IP address before an anonymous user is created,
+// answering 429 Too Many Requests and reporting false if there is none.
+func allowNewUser(w http.ResponseWriter, r *http.Request) bool {
+	if NewUsersRateLimit.Disabled() {
+		return true
+	}
+	return takeRateLimit(w, r, ipRateLimitKey(constants.CreateRateLimitScope, r), NewUsersRateLimit)
+}
+
+// takeRateLimit takes a token from the bucket of the key and sets the rate limit headers.
+// If the bucket is empty, it answers 429 Too Many Requests and reports false.
+// The buckets get constants.RateLimitTimeout to answer, so that a slow store does not hold up requests.
+func takeRateLimit(w http.ResponseWriter, r *http.Request, key string, limit models.RateLimit) bool {
+	if RateLimits == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), constants.RateLimitTimeout)
+	defer cancel()
+
+	bucket, err := RateLimits.Take(ctx, key, limit, time.Now())
+	if err != nil {
+		adapters.GetLogger().Infow("Error while taking rate limit token", "key", key, "error", err.Error())
+		return true
+	}
+
+	rate := limit.Rate()
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(limit.Requests))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(int(bucket.Tokens)))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds((float64(limit.Requests)-bucket.Tokens)/rate)))
+	if !bucket.Allowed {
+		h.Set("Retry-After", strconv.Itoa(max(ceilSeconds((1-bucket.Tokens)/rate), 1)))
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
+// rateLimitKey identifies the bucket of a request within a scope by its user or client IP address.
+func rateLimitKey(scope string, r *http.Request) string {
+	if userID, ok := r.Context().Value(constants.UserIDKey).(int64); ok {
+		return scope + ":user:" + strconv.FormatInt(userID, 10)
+	}
+	return ipRateLimitKey(scope, r)
+}
+
+// ipRateLimitKey identifies the bucket of a request within a scope by its client IP address.
+func ipRateLimitKey(scope string, r *http.Request) string {
+	return scope + ":ip:" + ClientIP(r)
+}
+
+// ceilSeconds rounds a non-negative number of seconds up to a whole second.
+func ceilSeconds(seconds float64) int {
+	return int(math.Ceil(max(seconds, 0)))
+}
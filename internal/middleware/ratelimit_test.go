@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"main/internal/constants"
+	"main/internal/mocks"
+	"main/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    models.RateLimit
+		wantErr bool
+	}{
+		{spec: "100/1m", want: models.RateLimit{Requests: 100, Period: time.Minute}},
+		{spec: "5/s", want: models.RateLimit{Requests: 5, Period: time.Second}},
+		{spec: " 10/90s ", want: models.RateLimit{Requests: 10, Period: 90 * time.Second}},
+		{spec: "off", want: models.RateLimit{}},
+		{spec: "100", wantErr: true},
+		{spec: "0/1m", wantErr: true},
+		{spec: "x/1m", wantErr: true},
+		{spec: "10/", wantErr: true},
+		{spec: "10/-1m", wantErr: true},
+		{spec: "10/fortnight", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			got, err := ParseRateLimit(test.spec)
+			if test.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRateLimit)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	limit := models.RateLimit{Requests: 10, Period: 10 * time.Second}
+
+	tests := []struct {
+		name        string
+		userID      int64
+		newUser     bool
+		bucket      models.RateLimitBucket
+		takeErr     error
+		wantKey     string
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "allowed by ip",
+			bucket:      models.RateLimitBucket{Tokens: 7.5, Allowed: true},
+			wantKey:     "create:ip:203.0.113.7",
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"X-RateLimit-Limit": "10", "X-RateLimit-Remaining": "7", "X-RateLimit-Reset": "3"},
+		},
+		{
+			name:        "allowed by user",
+			userID:      42,
+			bucket:      models.RateLimitBucket{Tokens: 9, Allowed: true},
+			wantKey:     "create:user:42",
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"X-RateLimit-Remaining": "9", "X-RateLimit-Reset": "1"},
+		},
+		{
+			name:       "new anonymous user limited by authentication",
+			userID:     43,
+			newUser:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "rejected",
+			bucket:      models.RateLimitBucket{Tokens: 0.25, Allowed: false},
+			wantKey:     "create:ip:203.0.113.7",
+			wantStatus:  http.StatusTooManyRequests,
+			wantHeaders: map[string]string{"X-RateLimit-Remaining": "0", "Retry-After": "1", "X-RateLimit-Reset": "10"},
+		},
+		{
+			name:       "store failure lets requests through",
+			takeErr:    errors.New("connection refused"),
+			wantKey:    "create:ip:203.0.113.7",
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockRateLimitRepository(ctrl)
+			if test.wantKey != "" {
+				store.EXPECT().Take(gomock.Any(), test.wantKey, limit, gomock.Any()).Return(test.bucket, test.takeErr)
+			}
+			RateLimits = store
+			defer func() { RateLimits = nil }()
+
+			var called bool
+			handler := RateLimit(constants.CreateRateLimitScope, limit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+
+			request := httptest.NewRequest(http.MethodPost, "/", nil)
+			request.RemoteAddr = "203.0.113.7:54321"
+			if test.userID != 0 {
+				ctx := context.WithValue(request.Context(), constants.UserIDKey, test.userID)
+				if test.newUser {
+					ctx = context.WithValue(ctx, constants.NewUserKey, true)
+				}
+				request = request.WithContext(ctx)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, request)
+
+			assert.Equal(t, test.wantStatus, w.Code)
+			assert.Equal(t, test.wantStatus == http.StatusOK, called)
+			for header, value := range test.wantHeaders {
+				assert.Equal(t, value, w.Header().Get(header), header)
+			}
+		})
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	RateLimits = mocks.NewMockRateLimitRepository(ctrl)
+	defer func() { RateLimits = nil }()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := RateLimit(constants.RedirectRateLimitScope, models.RateLimit{})(next)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+}
+
+func TestAuthenticationNewUsersRateLimit(t *testing.T) {
+	limit := models.RateLimit{Requests: 1, Period: time.Minute}
+
+	tests := []struct {
+		name       string
+		allowed    bool
+		logins     int
+		wantStatus int
+	}{
+		{name: "allowed", allowed: true, logins: 1, wantStatus: http.StatusOK},
+		{name: "throttled before the user is created", wantStatus: http.StatusTooManyRequests},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockRateLimitRepository(ctrl)
+			store.EXPECT().Take(gomock.Any(), "create:ip:203.0.113.7", limit, gomock.Any()).
+				Return(models.RateLimitBucket{Allowed: test.allowed}, nil)
+			RateLimits, NewUsersRateLimit = store, limit
+			defer func() { RateLimits, NewUsersRateLimit = nil, models.RateLimit{} }()
+
+			usersService := mocks.NewMockUsersService(ctrl)
+			usersService.EXPECT().Login().Return(int64(7), nil).Times(test.logins)
+			usersService.EXPECT().IssueRefreshToken(gomock.Any(), int64(7)).Return("refresh", nil).Times(test.logins)
+			UserService = usersService
+			defer func() { UserService = nil }()
+
+			var err error
+			Keys, err = NewKeySet("secret", "")
+			require.NoError(t, err)
+
+			// The route limiter must not take a second token for the user created by the request.
+			handler := Authentication(LazyPolicy)(RateLimit(constants.CreateRateLimitScope, limit)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+			request := httptest.NewRequest(http.MethodPost, "/", nil)
+			request.RemoteAddr = "203.0.113.7:54321"
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, request)
+
+			assert.Equal(t, test.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestRateLimitSlowStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	limit := models.RateLimit{Requests: 10, Period: time.Minute}
+	store := mocks.NewMockRateLimitRepository(ctrl)
+	store.EXPECT().Take(gomock.Any(), gomock.Any(), limit, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, key string, limit models.RateLimit, now time.Time) (models.RateLimitBucket, error) {
+			<-ctx.Done()
+			return models.RateLimitBucket{}, ctx.Err()
+		})
+	RateLimits = store
+	defer func() { RateLimits = nil }()
+
+	var called bool
+	handler := RateLimit(constants.RedirectRateLimitScope, limit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.True(t, called, "requests are let through when the store does not answer in time")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Less(t, time.Since(start), time.Second)
+}
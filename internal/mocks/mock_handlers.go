@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: main/internal/interfaces (interfaces: HealthHandlers,LinkHandlers,UsersHandlers)
+// Source: main/internal/interfaces (interfaces: HealthHandlers,LinkHandlers,UsersHandlers,ClicksHandlers)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -117,6 +117,30 @@ func (mr *MockLinkHandlersMockRecorder) GetLink(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLink", reflect.TypeOf((*MockLinkHandlers)(nil).GetLink), arg0, arg1)
 }
 
+// ImportLinks mocks base method.
+func (m *MockLinkHandlers) ImportLinks(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ImportLinks", arg0, arg1)
+}
+
+// ImportLinks indicates an expected call of ImportLinks.
+func (mr *MockLinkHandlersMockRecorder) ImportLinks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportLinks", reflect.TypeOf((*MockLinkHandlers)(nil).ImportLinks), arg0, arg1)
+}
+
+// UpdateLink mocks base method.
+func (m *MockLinkHandlers) UpdateLink(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateLink", arg0, arg1)
+}
+
+// UpdateLink indicates an expected call of UpdateLink.
+func (mr *MockLinkHandlersMockRecorder) UpdateLink(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLink", reflect.TypeOf((*MockLinkHandlers)(nil).UpdateLink), arg0, arg1)
+}
+
 // MockUsersHandlers is a mock of UsersHandlers interface.
 type MockUsersHandlers struct {
 	ctrl     *gomock.Controller
@@ -140,6 +164,18 @@ func (m *MockUsersHandlers) EXPECT() *MockUsersHandlersMockRecorder {
 	return m.recorder
 }
 
+// CreateAPIKey mocks base method.
+func (m *MockUsersHandlers) CreateAPIKey(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockUsersHandlersMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockUsersHandlers)(nil).CreateAPIKey), arg0, arg1)
+}
+
 // DeleteLinks mocks base method.
 func (m *MockUsersHandlers) DeleteLinks(arg0 http.ResponseWriter, arg1 *http.Request) {
 	m.ctrl.T.Helper()
@@ -152,6 +188,30 @@ func (mr *MockUsersHandlersMockRecorder) DeleteLinks(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLinks", reflect.TypeOf((*MockUsersHandlers)(nil).DeleteLinks), arg0, arg1)
 }
 
+// ExportLinks mocks base method.
+func (m *MockUsersHandlers) ExportLinks(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ExportLinks", arg0, arg1)
+}
+
+// ExportLinks indicates an expected call of ExportLinks.
+func (mr *MockUsersHandlersMockRecorder) ExportLinks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportLinks", reflect.TypeOf((*MockUsersHandlers)(nil).ExportLinks), arg0, arg1)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockUsersHandlers) GetAPIKeys(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAPIKeys", arg0, arg1)
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockUsersHandlersMockRecorder) GetAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockUsersHandlers)(nil).GetAPIKeys), arg0, arg1)
+}
+
 // GetLinks mocks base method.
 func (m *MockUsersHandlers) GetLinks(arg0 http.ResponseWriter, arg1 *http.Request) {
 	m.ctrl.T.Helper()
@@ -163,3 +223,98 @@ func (mr *MockUsersHandlersMockRecorder) GetLinks(arg0, arg1 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinks", reflect.TypeOf((*MockUsersHandlers)(nil).GetLinks), arg0, arg1)
 }
+
+// Login mocks base method.
+func (m *MockUsersHandlers) Login(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Login", arg0, arg1)
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockUsersHandlersMockRecorder) Login(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUsersHandlers)(nil).Login), arg0, arg1)
+}
+
+// Logout mocks base method.
+func (m *MockUsersHandlers) Logout(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Logout", arg0, arg1)
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockUsersHandlersMockRecorder) Logout(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockUsersHandlers)(nil).Logout), arg0, arg1)
+}
+
+// Register mocks base method.
+func (m *MockUsersHandlers) Register(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Register", arg0, arg1)
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockUsersHandlersMockRecorder) Register(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUsersHandlers)(nil).Register), arg0, arg1)
+}
+
+// RestoreLinks mocks base method.
+func (m *MockUsersHandlers) RestoreLinks(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RestoreLinks", arg0, arg1)
+}
+
+// RestoreLinks indicates an expected call of RestoreLinks.
+func (mr *MockUsersHandlersMockRecorder) RestoreLinks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreLinks", reflect.TypeOf((*MockUsersHandlers)(nil).RestoreLinks), arg0, arg1)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockUsersHandlers) RevokeAPIKey(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1)
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockUsersHandlersMockRecorder) RevokeAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockUsersHandlers)(nil).RevokeAPIKey), arg0, arg1)
+}
+
+// MockClicksHandlers is a mock of ClicksHandlers interface.
+type MockClicksHandlers struct {
+	ctrl     *gomock.Controller
+	recorder *MockClicksHandlersMockRecorder
+}
+
+// MockClicksHandlersMockRecorder is the mock recorder for MockClicksHandlers.
+type MockClicksHandlersMockRecorder struct {
+	mock *MockClicksHandlers
+}
+
+// NewMockClicksHandlers creates a new mock instance.
+func NewMockClicksHandlers(ctrl *gomock.Controller) *MockClicksHandlers {
+	mock := &MockClicksHandlers{ctrl: ctrl}
+	mock.recorder = &MockClicksHandlersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClicksHandlers) EXPECT() *MockClicksHandlersMockRecorder {
+	return m.recorder
+}
+
+// GetStats mocks base method.
+func (m *MockClicksHandlers) GetStats(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetStats", arg0, arg1)
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockClicksHandlersMockRecorder) GetStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockClicksHandlers)(nil).GetStats), arg0, arg1)
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: main/internal/interfaces (interfaces: HealthRepository,LinksRepository,FileStorageProducer,FileStorageConsumer,UsersRepository)
+// Source: main/internal/interfaces (interfaces: HealthRepository,LinksRepository,FileStorageProducer,FileStorageConsumer,UsersRepository,ClicksRepository,RateLimitRepository)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -8,6 +8,7 @@ import (
 	context "context"
 	models "main/internal/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -102,6 +103,36 @@ func (mr *MockLinksRepositoryMockRecorder) AddBatch(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBatch", reflect.TypeOf((*MockLinksRepository)(nil).AddBatch), arg0, arg1)
 }
 
+// DeleteExpired mocks base method.
+func (m *MockLinksRepository) DeleteExpired(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockLinksRepositoryMockRecorder) DeleteExpired(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockLinksRepository)(nil).DeleteExpired), arg0, arg1)
+}
+
+// DeleteSoftDeleted mocks base method.
+func (m *MockLinksRepository) DeleteSoftDeleted(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSoftDeleted", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSoftDeleted indicates an expected call of DeleteSoftDeleted.
+func (mr *MockLinksRepositoryMockRecorder) DeleteSoftDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSoftDeleted", reflect.TypeOf((*MockLinksRepository)(nil).DeleteSoftDeleted), arg0, arg1)
+}
+
 // Get mocks base method.
 func (m *MockLinksRepository) Get(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -117,6 +148,35 @@ func (mr *MockLinksRepositoryMockRecorder) Get(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLinksRepository)(nil).Get), arg0, arg1)
 }
 
+// Import mocks base method.
+func (m *MockLinksRepository) Import(arg0 context.Context, arg1 []models.AddedLink) ([]models.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1)
+	ret0, _ := ret[0].([]models.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockLinksRepositoryMockRecorder) Import(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockLinksRepository)(nil).Import), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockLinksRepository) Update(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockLinksRepositoryMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockLinksRepository)(nil).Update), arg0, arg1, arg2)
+}
+
 // MockFileStorageProducer is a mock of FileStorageProducer interface.
 type MockFileStorageProducer struct {
 	ctrl     *gomock.Controller
@@ -243,8 +303,65 @@ func (m *MockUsersRepository) EXPECT() *MockUsersRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddAPIKey mocks base method.
+func (m *MockUsersRepository) AddAPIKey(arg0 context.Context, arg1 models.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAPIKey indicates an expected call of AddAPIKey.
+func (mr *MockUsersRepositoryMockRecorder) AddAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAPIKey", reflect.TypeOf((*MockUsersRepository)(nil).AddAPIKey), arg0, arg1)
+}
+
+// AddRefreshToken mocks base method.
+func (m *MockUsersRepository) AddRefreshToken(arg0 context.Context, arg1 models.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRefreshToken indicates an expected call of AddRefreshToken.
+func (mr *MockUsersRepositoryMockRecorder) AddRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRefreshToken", reflect.TypeOf((*MockUsersRepository)(nil).AddRefreshToken), arg0, arg1)
+}
+
+// ClaimLinks mocks base method.
+func (m *MockUsersRepository) ClaimLinks(arg0 context.Context, arg1, arg2 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimLinks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimLinks indicates an expected call of ClaimLinks.
+func (mr *MockUsersRepositoryMockRecorder) ClaimLinks(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimLinks", reflect.TypeOf((*MockUsersRepository)(nil).ClaimLinks), arg0, arg1, arg2)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockUsersRepository) DeleteAPIKey(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockUsersRepositoryMockRecorder) DeleteAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockUsersRepository)(nil).DeleteAPIKey), arg0, arg1)
+}
+
 // DeleteLinks mocks base method.
-func (m *MockUsersRepository) DeleteLinks(arg0 context.Context, arg1 []string) error {
+func (m *MockUsersRepository) DeleteLinks(arg0 context.Context, arg1 []models.LinkDeletion) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteLinks", arg0, arg1)
 	ret0, _ := ret[0].(error)
@@ -257,19 +374,122 @@ func (mr *MockUsersRepositoryMockRecorder) DeleteLinks(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLinks", reflect.TypeOf((*MockUsersRepository)(nil).DeleteLinks), arg0, arg1)
 }
 
+// DeleteRefreshToken mocks base method.
+func (m *MockUsersRepository) DeleteRefreshToken(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRefreshToken indicates an expected call of DeleteRefreshToken.
+func (mr *MockUsersRepositoryMockRecorder) DeleteRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshToken", reflect.TypeOf((*MockUsersRepository)(nil).DeleteRefreshToken), arg0, arg1)
+}
+
+// ExportLinks mocks base method.
+func (m *MockUsersRepository) ExportLinks(arg0 context.Context, arg1 func(models.ExportedLink) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportLinks", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportLinks indicates an expected call of ExportLinks.
+func (mr *MockUsersRepositoryMockRecorder) ExportLinks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportLinks", reflect.TypeOf((*MockUsersRepository)(nil).ExportLinks), arg0, arg1)
+}
+
+// ExtendRefreshToken mocks base method.
+func (m *MockUsersRepository) ExtendRefreshToken(arg0 context.Context, arg1 string, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendRefreshToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtendRefreshToken indicates an expected call of ExtendRefreshToken.
+func (mr *MockUsersRepositoryMockRecorder) ExtendRefreshToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendRefreshToken", reflect.TypeOf((*MockUsersRepository)(nil).ExtendRefreshToken), arg0, arg1, arg2)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockUsersRepository) GetAPIKeys(arg0 context.Context) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", arg0)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockUsersRepositoryMockRecorder) GetAPIKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockUsersRepository)(nil).GetAPIKeys), arg0)
+}
+
 // GetLinks mocks base method.
-func (m *MockUsersRepository) GetLinks(arg0 context.Context) ([]models.UserLinks, error) {
+func (m *MockUsersRepository) GetLinks(arg0 context.Context, arg1 models.LinksQuery) ([]models.UserLinks, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLinks", arg0)
+	ret := m.ctrl.Call(m, "GetLinks", arg0, arg1)
 	ret0, _ := ret[0].([]models.UserLinks)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetLinks indicates an expected call of GetLinks.
-func (mr *MockUsersRepositoryMockRecorder) GetLinks(arg0 interface{}) *gomock.Call {
+func (mr *MockUsersRepositoryMockRecorder) GetLinks(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinks", reflect.TypeOf((*MockUsersRepository)(nil).GetLinks), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinks", reflect.TypeOf((*MockUsersRepository)(nil).GetLinks), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockUsersRepository) GetUser(arg0 context.Context, arg1 int64) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockUsersRepositoryMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUsersRepository)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserByAPIKey mocks base method.
+func (m *MockUsersRepository) GetUserByAPIKey(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByAPIKey indicates an expected call of GetUserByAPIKey.
+func (mr *MockUsersRepositoryMockRecorder) GetUserByAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByAPIKey", reflect.TypeOf((*MockUsersRepository)(nil).GetUserByAPIKey), arg0, arg1)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockUsersRepository) GetUserByUsername(arg0 context.Context, arg1 string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", arg0, arg1)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockUsersRepositoryMockRecorder) GetUserByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUsersRepository)(nil).GetUserByUsername), arg0, arg1)
 }
 
 // Login mocks base method.
@@ -286,3 +506,137 @@ func (mr *MockUsersRepositoryMockRecorder) Login(arg0 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUsersRepository)(nil).Login), arg0)
 }
+
+// RestoreLinks mocks base method.
+func (m *MockUsersRepository) RestoreLinks(arg0 context.Context, arg1 []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreLinks", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreLinks indicates an expected call of RestoreLinks.
+func (mr *MockUsersRepositoryMockRecorder) RestoreLinks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreLinks", reflect.TypeOf((*MockUsersRepository)(nil).RestoreLinks), arg0, arg1)
+}
+
+// SetCredentials mocks base method.
+func (m *MockUsersRepository) SetCredentials(arg0 context.Context, arg1 int64, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCredentials", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCredentials indicates an expected call of SetCredentials.
+func (mr *MockUsersRepositoryMockRecorder) SetCredentials(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCredentials", reflect.TypeOf((*MockUsersRepository)(nil).SetCredentials), arg0, arg1, arg2, arg3)
+}
+
+// MockClicksRepository is a mock of ClicksRepository interface.
+type MockClicksRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockClicksRepositoryMockRecorder
+}
+
+// MockClicksRepositoryMockRecorder is the mock recorder for MockClicksRepository.
+type MockClicksRepositoryMockRecorder struct {
+	mock *MockClicksRepository
+}
+
+// NewMockClicksRepository creates a new mock instance.
+func NewMockClicksRepository(ctrl *gomock.Controller) *MockClicksRepository {
+	mock := &MockClicksRepository{ctrl: ctrl}
+	mock.recorder = &MockClicksRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClicksRepository) EXPECT() *MockClicksRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddBatch mocks base method.
+func (m *MockClicksRepository) AddBatch(arg0 context.Context, arg1 []models.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBatch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddBatch indicates an expected call of AddBatch.
+func (mr *MockClicksRepositoryMockRecorder) AddBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBatch", reflect.TypeOf((*MockClicksRepository)(nil).AddBatch), arg0, arg1)
+}
+
+// GetStats mocks base method.
+func (m *MockClicksRepository) GetStats(arg0 context.Context, arg1 string) (models.LinkStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", arg0, arg1)
+	ret0, _ := ret[0].(models.LinkStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockClicksRepositoryMockRecorder) GetStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockClicksRepository)(nil).GetStats), arg0, arg1)
+}
+
+// MockRateLimitRepository is a mock of RateLimitRepository interface.
+type MockRateLimitRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitRepositoryMockRecorder
+}
+
+// MockRateLimitRepositoryMockRecorder is the mock recorder for MockRateLimitRepository.
+type MockRateLimitRepositoryMockRecorder struct {
+	mock *MockRateLimitRepository
+}
+
+// NewMockRateLimitRepository creates a new mock instance.
+func NewMockRateLimitRepository(ctrl *gomock.Controller) *MockRateLimitRepository {
+	mock := &MockRateLimitRepository{ctrl: ctrl}
+	mock.recorder = &MockRateLimitRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimitRepository) EXPECT() *MockRateLimitRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteIdle mocks base method.
+func (m *MockRateLimitRepository) DeleteIdle(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdle", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteIdle indicates an expected call of DeleteIdle.
+func (mr *MockRateLimitRepositoryMockRecorder) DeleteIdle(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdle", reflect.TypeOf((*MockRateLimitRepository)(nil).DeleteIdle), arg0, arg1)
+}
+
+// Take mocks base method.
+func (m *MockRateLimitRepository) Take(arg0 context.Context, arg1 string, arg2 models.RateLimit, arg3 time.Time) (models.RateLimitBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Take", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(models.RateLimitBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Take indicates an expected call of Take.
+func (mr *MockRateLimitRepositoryMockRecorder) Take(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Take", reflect.TypeOf((*MockRateLimitRepository)(nil).Take), arg0, arg1, arg2, arg3)
+}
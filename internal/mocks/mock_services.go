@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: main/internal/interfaces (interfaces: HealthService,LinksService,UsersService)
+// Source: main/internal/interfaces (interfaces: HealthService,LinksService,UsersService,ClicksService,LinksDeleter)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -117,6 +117,65 @@ func (mr *MockLinksServiceMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLinksService)(nil).Get), arg0, arg1)
 }
 
+// Import mocks base method.
+func (m *MockLinksService) Import(arg0 context.Context, arg1 []models.OriginLink, arg2 string) ([]models.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockLinksServiceMockRecorder) Import(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockLinksService)(nil).Import), arg0, arg1, arg2)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockLinksService) PurgeDeleted(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockLinksServiceMockRecorder) PurgeDeleted(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockLinksService)(nil).PurgeDeleted), arg0)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockLinksService) PurgeExpired(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockLinksServiceMockRecorder) PurgeExpired(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockLinksService)(nil).PurgeExpired), arg0)
+}
+
+// Update mocks base method.
+func (m *MockLinksService) Update(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockLinksServiceMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockLinksService)(nil).Update), arg0, arg1, arg2)
+}
+
 // MockUsersService is a mock of UsersService interface.
 type MockUsersService struct {
 	ctrl     *gomock.Controller
@@ -140,6 +199,52 @@ func (m *MockUsersService) EXPECT() *MockUsersServiceMockRecorder {
 	return m.recorder
 }
 
+// Authenticate mocks base method.
+func (m *MockUsersService) Authenticate(arg0 context.Context, arg1, arg2 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockUsersServiceMockRecorder) Authenticate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockUsersService)(nil).Authenticate), arg0, arg1, arg2)
+}
+
+// AuthenticateAPIKey mocks base method.
+func (m *MockUsersService) AuthenticateAPIKey(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockUsersServiceMockRecorder) AuthenticateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockUsersService)(nil).AuthenticateAPIKey), arg0, arg1)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockUsersService) CreateAPIKey(arg0 context.Context, arg1 string) (models.APIKey, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockUsersServiceMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockUsersService)(nil).CreateAPIKey), arg0, arg1)
+}
+
 // DeleteLinks mocks base method.
 func (m *MockUsersService) DeleteLinks(arg0 context.Context, arg1 []string) error {
 	m.ctrl.T.Helper()
@@ -154,19 +259,64 @@ func (mr *MockUsersServiceMockRecorder) DeleteLinks(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLinks", reflect.TypeOf((*MockUsersService)(nil).DeleteLinks), arg0, arg1)
 }
 
+// ExportLinks mocks base method.
+func (m *MockUsersService) ExportLinks(arg0 context.Context, arg1 string, arg2 func(models.ExportedLink) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportLinks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportLinks indicates an expected call of ExportLinks.
+func (mr *MockUsersServiceMockRecorder) ExportLinks(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportLinks", reflect.TypeOf((*MockUsersService)(nil).ExportLinks), arg0, arg1, arg2)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockUsersService) GetAPIKeys(arg0 context.Context) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", arg0)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockUsersServiceMockRecorder) GetAPIKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockUsersService)(nil).GetAPIKeys), arg0)
+}
+
 // GetLinks mocks base method.
-func (m *MockUsersService) GetLinks(arg0 context.Context, arg1 string) ([]models.UserLinks, error) {
+func (m *MockUsersService) GetLinks(arg0 context.Context, arg1 string, arg2 models.LinksQuery, arg3 string) ([]models.UserLinks, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLinks", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetLinks", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]models.UserLinks)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLinks indicates an expected call of GetLinks.
+func (mr *MockUsersServiceMockRecorder) GetLinks(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinks", reflect.TypeOf((*MockUsersService)(nil).GetLinks), arg0, arg1, arg2, arg3)
+}
+
+// IssueRefreshToken mocks base method.
+func (m *MockUsersService) IssueRefreshToken(arg0 context.Context, arg1 int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetLinks indicates an expected call of GetLinks.
-func (mr *MockUsersServiceMockRecorder) GetLinks(arg0, arg1 interface{}) *gomock.Call {
+// IssueRefreshToken indicates an expected call of IssueRefreshToken.
+func (mr *MockUsersServiceMockRecorder) IssueRefreshToken(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinks", reflect.TypeOf((*MockUsersService)(nil).GetLinks), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueRefreshToken", reflect.TypeOf((*MockUsersService)(nil).IssueRefreshToken), arg0, arg1)
 }
 
 // Login mocks base method.
@@ -183,3 +333,187 @@ func (mr *MockUsersServiceMockRecorder) Login() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUsersService)(nil).Login))
 }
+
+// Logout mocks base method.
+func (m *MockUsersService) Logout(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockUsersServiceMockRecorder) Logout(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockUsersService)(nil).Logout), arg0, arg1)
+}
+
+// RefreshSession mocks base method.
+func (m *MockUsersService) RefreshSession(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSession", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshSession indicates an expected call of RefreshSession.
+func (mr *MockUsersServiceMockRecorder) RefreshSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSession", reflect.TypeOf((*MockUsersService)(nil).RefreshSession), arg0, arg1)
+}
+
+// Register mocks base method.
+func (m *MockUsersService) Register(arg0 context.Context, arg1, arg2 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockUsersServiceMockRecorder) Register(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUsersService)(nil).Register), arg0, arg1, arg2)
+}
+
+// RestoreLinks mocks base method.
+func (m *MockUsersService) RestoreLinks(arg0 context.Context, arg1 []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreLinks", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreLinks indicates an expected call of RestoreLinks.
+func (mr *MockUsersServiceMockRecorder) RestoreLinks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreLinks", reflect.TypeOf((*MockUsersService)(nil).RestoreLinks), arg0, arg1)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockUsersService) RevokeAPIKey(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockUsersServiceMockRecorder) RevokeAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockUsersService)(nil).RevokeAPIKey), arg0, arg1)
+}
+
+// MockClicksService is a mock of ClicksService interface.
+type MockClicksService struct {
+	ctrl     *gomock.Controller
+	recorder *MockClicksServiceMockRecorder
+}
+
+// MockClicksServiceMockRecorder is the mock recorder for MockClicksService.
+type MockClicksServiceMockRecorder struct {
+	mock *MockClicksService
+}
+
+// NewMockClicksService creates a new mock instance.
+func NewMockClicksService(ctrl *gomock.Controller) *MockClicksService {
+	mock := &MockClicksService{ctrl: ctrl}
+	mock.recorder = &MockClicksServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClicksService) EXPECT() *MockClicksServiceMockRecorder {
+	return m.recorder
+}
+
+// GetStats mocks base method.
+func (m *MockClicksService) GetStats(arg0 context.Context, arg1, arg2 string) (models.LinkStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.LinkStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockClicksServiceMockRecorder) GetStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockClicksService)(nil).GetStats), arg0, arg1, arg2)
+}
+
+// Record mocks base method.
+func (m *MockClicksService) Record(arg0, arg1, arg2, arg3 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", arg0, arg1, arg2, arg3)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockClicksServiceMockRecorder) Record(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockClicksService)(nil).Record), arg0, arg1, arg2, arg3)
+}
+
+// Run mocks base method.
+func (m *MockClicksService) Run(arg0 context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", arg0)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockClicksServiceMockRecorder) Run(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockClicksService)(nil).Run), arg0)
+}
+
+// MockLinksDeleter is a mock of LinksDeleter interface.
+type MockLinksDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockLinksDeleterMockRecorder
+}
+
+// MockLinksDeleterMockRecorder is the mock recorder for MockLinksDeleter.
+type MockLinksDeleterMockRecorder struct {
+	mock *MockLinksDeleter
+}
+
+// NewMockLinksDeleter creates a new mock instance.
+func NewMockLinksDeleter(ctrl *gomock.Controller) *MockLinksDeleter {
+	mock := &MockLinksDeleter{ctrl: ctrl}
+	mock.recorder = &MockLinksDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLinksDeleter) EXPECT() *MockLinksDeleterMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockLinksDeleter) Enqueue(arg0 int64, arg1 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockLinksDeleterMockRecorder) Enqueue(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockLinksDeleter)(nil).Enqueue), arg0, arg1)
+}
+
+// Run mocks base method.
+func (m *MockLinksDeleter) Run(arg0 context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", arg0)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockLinksDeleterMockRecorder) Run(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockLinksDeleter)(nil).Run), arg0)
+}
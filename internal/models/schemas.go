@@ -1,8 +1,13 @@
 package models
 
+import "time"
+
 // ShortenRequest represents a single link shortening request.
 type ShortenRequest struct {
-	URL string `json:"url,omitempty"` // Optional field for the URL to be shortened.
+	URL       string     `json:"url,omitempty"`        // Optional field for the URL to be shortened.
+	Alias     string     `json:"alias,omitempty"`      // Optional custom short code requested by the client.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Optional moment after which the link expires.
+	TTL       int64      `json:"ttl,omitempty"`        // Optional link lifetime in seconds, ignored if expires_at is set.
 }
 
 // ShortenResponse carries the result of a link shortening operation.
@@ -12,14 +17,32 @@ type ShortenResponse struct {
 
 // ShortensRequest encapsulates a batch link shortening request item.
 type ShortensRequest struct {
-	CorrelationID string `json:"correlation_id,omitempty"` // Unique correlation ID for traceability.
-	URL           string `json:"original_url,omitempty"`   // Original URL to be shortened.
+	CorrelationID string     `json:"correlation_id,omitempty"` // Unique correlation ID for traceability.
+	URL           string     `json:"original_url,omitempty"`   // Original URL to be shortened.
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`     // Optional moment after which the link expires.
+	TTL           int64      `json:"ttl,omitempty"`            // Optional link lifetime in seconds, ignored if expires_at is set.
 }
 
 // ShortensResponse conveys the result of a batch link shortening operation.
 type ShortensResponse struct {
 	CorrelationID string `json:"correlation_id,omitempty"` // Corresponding correlation ID.
-	Result        string `json:"short_url"`                // Generated short URL.
+	Result        string `json:"short_url"`                // Generated or existing short URL, or the reason the item was rejected.
+	Status        string `json:"status"`                   // Outcome of the item: created, exists or invalid.
+}
+
+// ImportLinkRequest is a single row of a bulk link import.
+type ImportLinkRequest struct {
+	Short     string     `json:"short,omitempty"`      // Short code to preserve, a new one is generated if empty or taken.
+	URL       string     `json:"original_url"`         // Original URL.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Optional moment after which the link expires.
+}
+
+// ImportLinkResponse reports the outcome of a single row of a bulk link import.
+type ImportLinkResponse struct {
+	Row    int    `json:"row,omitempty"`       // Number of the row in the input, starting at one.
+	Result string `json:"short_url,omitempty"` // Short URL of the link.
+	Status string `json:"status"`              // Outcome of the row: created, renamed, exists, invalid or aborted.
+	Error  string `json:"error,omitempty"`     // Reason the row was rejected or the import aborted.
 }
 
 // UserLinksResponse represents a user-facing link summary with both short and original URLs.
@@ -27,3 +50,67 @@ type UserLinksResponse struct {
 	Shorten  string `json:"short_url"`    // Shortened URL.
 	Original string `json:"original_url"` // Original URL.
 }
+
+// UserLinksPageResponse is a page of the user's links along with the cursor of the next page.
+type UserLinksPageResponse struct {
+	URLs       []UserLinksResponse `json:"urls"`                  // Links of the page.
+	NextCursor string              `json:"next_cursor,omitempty"` // Cursor of the next page, empty on the last page.
+}
+
+// ExportLinkResponse is a single link of an export.
+type ExportLinkResponse struct {
+	Short     string    `json:"short"`        // Short code of the link.
+	ShortURL  string    `json:"short_url"`    // Full short URL.
+	Original  string    `json:"original_url"` // Original URL.
+	CreatedAt time.Time `json:"created_at"`   // Moment the link was created.
+	Deleted   bool      `json:"deleted"`      // Indicates whether the link is soft-deleted.
+	Clicks    int64     `json:"clicks"`       // Number of redirects through the link.
+}
+
+// UpdateLinkRequest carries the new destination of an existing short link.
+type UpdateLinkRequest struct {
+	URL string `json:"url"` // New original URL.
+}
+
+// RestoreLinksResponse reports how many links were restored.
+type RestoreLinksResponse struct {
+	Restored int64 `json:"restored"` // Number of links undeleted.
+}
+
+// APIKeyRequest represents a request for issuing a new API key.
+type APIKeyRequest struct {
+	Name string `json:"name"` // Human-readable name of the key.
+}
+
+// APIKeyResponse describes an API key; the key itself is only returned once, when it is issued.
+type APIKeyResponse struct {
+	ID        string    `json:"id"`            // Unique identifier of the key.
+	Name      string    `json:"name"`          // Human-readable name of the key.
+	Prefix    string    `json:"prefix"`        // First characters of the key.
+	CreatedAt time.Time `json:"created_at"`    // Moment the key was issued.
+	Key       string    `json:"key,omitempty"` // Plain key, present only in the response to its creation.
+}
+
+// CredentialsRequest carries the username and password of a registered account.
+type CredentialsRequest struct {
+	Username string `json:"username"` // Username of the account.
+	Password string `json:"password"` // Plain password of the account.
+}
+
+// AccountResponse describes the account a user is signed in to.
+type AccountResponse struct {
+	Username string `json:"username"` // Username of the account.
+}
+
+// DailyClicksResponse represents the number of clicks during a single day.
+type DailyClicksResponse struct {
+	Date   string `json:"date"`   // Day in YYYY-MM-DD format (UTC).
+	Clicks int64  `json:"clicks"` // Number of clicks during the day.
+}
+
+// LinkStatsResponse carries click statistics of a short link.
+type LinkStatsResponse struct {
+	Shorten string                `json:"short_url"` // Shortened URL.
+	Total   int64                 `json:"total"`     // Total number of clicks.
+	Daily   []DailyClicksResponse `json:"daily"`     // Clicks grouped by day.
+}
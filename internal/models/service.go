@@ -1,26 +1,117 @@
 package models
 
+import "time"
+
 // AddedLink captures the result of a successful link addition operation.
 type AddedLink struct {
-	CorrelationID string // Identifier correlating with the originating request.
-	Short         string // Generated short URL.
-	Origin        string // Original long URL.
+	CorrelationID string    // Identifier correlating with the originating request.
+	Short         string    // Generated short URL.
+	Origin        string    // Original long URL.
+	ExpiresAt     time.Time // Moment after which the link expires, zero if it never does.
 }
 
 // OriginLink represents a link submission for shortening.
 type OriginLink struct {
-	CorrelationID string // Identifier for tracking purposes.
-	URL           string // Long URL to be shortened.
+	CorrelationID string        // Identifier for tracking purposes.
+	URL           string        // Long URL to be shortened.
+	Alias         string        // Optional custom short code used instead of a generated one.
+	ExpiresAt     time.Time     // Optional moment after which the link expires.
+	TTL           time.Duration // Optional link lifetime, ignored if ExpiresAt is set.
 }
 
 // Result summarizes the outcome of a link shortening attempt.
 type Result struct {
 	CorrelationID string // Associated correlation ID.
 	Result        string // Final short URL or error message.
+	Status        string // Outcome of the attempt: created, exists or invalid.
 }
 
 // UserLinks pairs a short URL with its corresponding original URL.
 type UserLinks struct {
-	Shorten  string // Shortened URL.
-	Original string // Original URL.
+	Shorten   string    // Shortened URL.
+	Original  string    // Original URL.
+	CreatedAt time.Time // Moment the link was created, zero if unknown.
+	Clicks    int64     // Number of redirects through the link.
+}
+
+// ExportedLink is a link of a user as listed in an export.
+type ExportedLink struct {
+	Short     string    // Short code of the link.
+	ShortURL  string    // Full short URL, filled in by the service.
+	Original  string    // Original URL.
+	CreatedAt time.Time // Moment the link was created, zero if unknown.
+	Deleted   bool      // Indicates whether the link is soft-deleted.
+	Clicks    int64     // Number of redirects through the link.
+}
+
+// LinksQuery selects, filters and orders the links of the current user.
+type LinksQuery struct {
+	Deleted      bool         // List soft-deleted links instead of the active ones.
+	Limit        int          // Maximum number of links to return, zero for all of them.
+	After        *LinksCursor // Position of the last link of the previous page, nil for the first page.
+	SortBy       string       // Sort key, see the links sort constants.
+	Descending   bool         // Sort in descending order.
+	Origin       string       // Case-insensitive substring the original URL must contain, empty for any.
+	CreatedAfter time.Time    // Only links created after this moment are listed, zero for all of them.
+}
+
+// LinksCursor is the position of a link in a sorted listing of user links.
+type LinksCursor struct {
+	SortBy     string    `json:"k"`           // Sort key of the listing the cursor belongs to.
+	Descending bool      `json:"d,omitempty"` // Sort direction of the listing the cursor belongs to.
+	Filters    string    `json:"f"`           // Hash of the filters of the listing the cursor belongs to.
+	Short      string    `json:"s"`           // Short link, breaking ties between equal sort keys.
+	CreatedAt  time.Time `json:"t"`           // Creation time of the link.
+	Clicks     int64     `json:"c,omitempty"` // Number of clicks on the link.
+}
+
+// LinkDeletion is a queued request of a user to soft-delete one of their links.
+type LinkDeletion struct {
+	Short  string // Short link to delete.
+	UserID int64  // Identifier of the user requesting the deletion; only the owner's requests take effect.
+}
+
+// Click describes a single redirect through a short link.
+type Click struct {
+	Short     string    // Short link that was followed.
+	ClickedAt time.Time // Moment of the redirect.
+	Referrer  string    // Referer header of the request.
+	UserAgent string    // User-Agent header of the request.
+	IPHash    string    // Hex-encoded HMAC-SHA256 of the client IP address.
+}
+
+// DailyClicks holds the number of clicks registered during a single day.
+type DailyClicks struct {
+	Day    time.Time // Start of the day in UTC.
+	Clicks int64     // Number of clicks during the day.
+}
+
+// LinkStats aggregates click statistics of a short link.
+type LinkStats struct {
+	Short string        // Short link the statistics belong to.
+	Total int64         // Total number of clicks.
+	Daily []DailyClicks // Clicks grouped by day in ascending order.
+}
+
+// RateLimit is a token bucket budget of requests: up to Requests at once, refilled at Requests per Period.
+// A zero limit disables rate limiting.
+type RateLimit struct {
+	Requests int           // Capacity of the bucket and number of tokens refilled per period.
+	Period   time.Duration // Time it takes to refill an empty bucket.
+}
+
+// Disabled reports whether the limit lets every request through.
+func (l RateLimit) Disabled() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// Rate returns the number of tokens refilled per second.
+func (l RateLimit) Rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// RateLimitBucket is the state of a token bucket after a request took a token from it.
+type RateLimitBucket struct {
+	Tokens  float64 // Tokens left in the bucket.
+	Allowed bool    // Indicates whether the bucket had a token for the request.
 }
@@ -1,8 +1,48 @@
 package models
 
+import "time"
+
+// Types of events kept in the file storage.
+// Events written before types were introduced have an empty type and describe added links.
+const (
+	LinkAddedEvent      = "link_added"      // A short link was created.
+	UserAddedEvent      = "user_added"      // A user was registered.
+	LinkDeletedEvent    = "link_deleted"    // A short link was soft-deleted by its owner.
+	APIKeyAddedEvent    = "api_key_added"   // An API key was issued to a user.
+	APIKeyRevokedEvent  = "api_key_revoked" // An API key was revoked by its owner.
+	SessionSavedEvent   = "session_saved"   // A refresh token was issued or its expiry was extended.
+	SessionDeletedEvent = "session_deleted" // A refresh token was revoked on logout.
+	AccountAddedEvent   = "account_added"   // An anonymous user was given a username and password.
+	LinksClaimedEvent   = "links_claimed"   // Links of an anonymous user were moved to a registered account.
+	LinkUpdatedEvent    = "link_updated"    // The owner changed the destination of a link.
+	LinkRestoredEvent   = "link_restored"   // The owner undid the soft-deletion of a link.
+	LinkPurgedEvent     = "link_purged"     // A soft-deleted link was removed permanently.
+	ClicksAddedEvent    = "clicks_added"    // A batch of redirects through short links was recorded.
+)
+
 // Event tracks the history of link transformations.
 type Event struct {
-	Origin string `json:"original_url"` // Original URL being tracked.
-	Short  string `json:"short_url"`    // Shortened equivalent of the original URL.
-	ID     int    `json:"uuid"`         // Unique identifier for the event.
+	Origin         string        `json:"original_url"`           // Original URL being tracked.
+	PreviousOrigin string        `json:"previous_url,omitempty"` // Destination of the link before an update.
+	Short          string        `json:"short_url"`              // Shortened equivalent of the original URL.
+	ID             int           `json:"uuid"`                   // Unique identifier for the event.
+	ExpiresAt      *time.Time    `json:"expires_at,omitempty"`   // Moment after which the link expires, if any.
+	DeletedAt      *time.Time    `json:"deleted_at,omitempty"`   // Moment the link was soft-deleted, if any.
+	CreatedAt      *time.Time    `json:"created_at,omitempty"`   // Moment the link was created, if recorded.
+	Type           string        `json:"type,omitempty"`         // Kind of the event, see the event type constants.
+	UserID         int64         `json:"user_id,omitempty"`      // Identifier of the user the event belongs to.
+	APIKey         *APIKey       `json:"api_key,omitempty"`      // API key the event refers to, if any.
+	Session        *RefreshToken `json:"session,omitempty"`      // Refresh token the event refers to, if any.
+	Account        *User         `json:"account,omitempty"`      // Registered account the event refers to, if any.
+	FromUser       int64         `json:"from_user_id,omitempty"` // Anonymous user whose links were claimed.
+	Clicks         []StoredClick `json:"clicks,omitempty"`       // Recorded clicks, if any.
+}
+
+// StoredClick is a redirect through a short link as kept in the file storage.
+type StoredClick struct {
+	Short     string    `json:"short_url"`            // Short link that was followed.
+	ClickedAt time.Time `json:"clicked_at"`           // Moment of the redirect.
+	Referrer  string    `json:"referrer,omitempty"`   // Referer header of the request.
+	UserAgent string    `json:"user_agent,omitempty"` // User-Agent header of the request.
+	IPHash    string    `json:"ip_hash,omitempty"`    // Keyed hash of the client IP address.
 }
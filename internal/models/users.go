@@ -1,12 +1,16 @@
 package models
 
-import "github.com/golang-jwt/jwt/v4"
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"time"
+)
 
 // User represents a user entity within the system.
+// Anonymous users have neither a username nor a password.
 type User struct {
-	Username string // Username for identification.
-	ID       int64  // Unique identifier for the user.
-
+	Username     string `json:"username"`      // Username for identification, empty for anonymous users.
+	ID           int64  `json:"id"`            // Unique identifier for the user.
+	PasswordHash string `json:"password_hash"` // Password hash in the bcrypt format.
 }
 
 // Claims extends the standard JWT claims with a custom user ID field.
@@ -15,3 +19,20 @@ type Claims struct {
 	UserID               int64 `json:"userId"` // Custom claim carrying the user ID.
 
 }
+
+// APIKey describes a named API key issued to a user. Only the hash of the key itself is stored.
+type APIKey struct {
+	ID        string    `json:"id"`         // Unique identifier of the key.
+	UserID    int64     `json:"user_id"`    // Identifier of the user owning the key.
+	Name      string    `json:"name"`       // Human-readable name given by the owner.
+	Prefix    string    `json:"prefix"`     // First characters of the key, shown to help recognize it.
+	Hash      string    `json:"hash"`       // Hex-encoded SHA-256 hash of the key.
+	CreatedAt time.Time `json:"created_at"` // Moment the key was issued.
+}
+
+// RefreshToken describes a server-side session used to reissue access tokens. Only the hash of the token is stored.
+type RefreshToken struct {
+	Hash      string    `json:"hash"`       // Hex-encoded SHA-256 hash of the token.
+	UserID    int64     `json:"user_id"`    // Identifier of the user owning the session.
+	ExpiresAt time.Time `json:"expires_at"` // Moment the session expires unless it is used again.
+}
@@ -0,0 +1,125 @@
+package services // Package services implements business logic for registered accounts.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"main/internal/constants"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Length limits for account credentials; bcrypt ignores password bytes beyond 72.
+const (
+	usernameMinLength = 3
+	usernameMaxLength = 64
+	passwordMinLength = 8
+	passwordMaxLength = 72
+)
+
+// dummyPasswordHash is compared against when a username is unknown,
+// so that signing in takes as long for missing accounts as for wrong passwords.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// Register creates an account with the given credentials and returns its user ID.
+// An anonymous user making the request becomes the account and keeps its links; otherwise a new user is created.
+func (s *UsersService) Register(ctx context.Context, username, password string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	username = strings.ToLower(username)
+	if err := validateCredentials(username, password); err != nil {
+		return 0, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	userID, err := s.anonymousUser(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if userID == 0 {
+		if userID, err = s.usersRepository.Login(ctx); err != nil {
+			return 0, ErrAddUser
+		}
+	}
+	if err := s.usersRepository.SetCredentials(ctx, userID, username, string(hash)); err != nil {
+		if errors.Is(err, ErrUsernameTaken) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to register user: %w", err)
+	}
+	return userID, nil
+}
+
+// Authenticate verifies the credentials of an account and returns its user ID.
+// Links of an anonymous user making the request are claimed into the account.
+func (s *UsersService) Authenticate(ctx context.Context, username, password string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	user, err := s.usersRepository.GetUserByUsername(ctx, strings.ToLower(username))
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return 0, fmt.Errorf("failed to get user: %w", err)
+	}
+	hash := []byte(user.PasswordHash)
+	if err != nil {
+		hash = dummyPasswordHash()
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || err != nil {
+		return 0, ErrBadCredentials
+	}
+
+	anonymousID, err := s.anonymousUser(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if anonymousID != 0 && anonymousID != user.ID {
+		if _, err := s.usersRepository.ClaimLinks(ctx, anonymousID, user.ID); err != nil {
+			return 0, fmt.Errorf("failed to claim links: %w", err)
+		}
+	}
+	return user.ID, nil
+}
+
+// anonymousUser returns the ID of the anonymous user making the request, or zero if the request
+// has no user or is made by a registered one.
+func (s *UsersService) anonymousUser(ctx context.Context) (int64, error) {
+	userID, ok := ctx.Value(constants.UserIDKey).(int64)
+	if !ok {
+		return 0, nil
+	}
+	user, err := s.usersRepository.GetUser(ctx, userID)
+	if errors.Is(err, ErrUserNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Username != "" {
+		return 0, nil
+	}
+	return user.ID, nil
+}
+
+// validateCredentials checks that a username has an allowed length and charset and a password an allowed length.
+func validateCredentials(username, password string) error {
+	if len(username) < usernameMinLength || len(username) > usernameMaxLength {
+		return fmt.Errorf("%w: length must be between %d and %d characters", ErrInvalidUsername, usernameMinLength, usernameMaxLength)
+	}
+	for _, r := range username {
+		if !isAliasRune(r) {
+			return fmt.Errorf("%w: character %q is not allowed", ErrInvalidUsername, r)
+		}
+	}
+	if len(password) < passwordMinLength || len(password) > passwordMaxLength {
+		return fmt.Errorf("%w: length must be between %d and %d bytes", ErrInvalidPassword, passwordMinLength, passwordMaxLength)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package services // Package services provides validation rules for custom short link aliases.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Length limits for custom aliases.
+const (
+	aliasMinLength = 3
+	aliasMaxLength = 64
+)
+
+// reservedAliases lists path segments already occupied by the service routes.
+var reservedAliases = map[string]bool{
+	"api":  true,
+	"ping": true,
+}
+
+// validateAlias checks that a custom alias has an allowed length, charset and is not reserved.
+func validateAlias(alias string) error {
+	if len(alias) < aliasMinLength || len(alias) > aliasMaxLength {
+		return fmt.Errorf("%w: length must be between %d and %d characters", ErrInvalidAlias, aliasMinLength, aliasMaxLength)
+	}
+	for _, r := range alias {
+		if !isAliasRune(r) {
+			return fmt.Errorf("%w: character %q is not allowed", ErrInvalidAlias, r)
+		}
+	}
+	if reservedAliases[strings.ToLower(alias)] {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidAlias, alias)
+	}
+	return nil
+}
+
+// isAliasRune reports whether a rune belongs to the alias charset: latin letters, digits, '-' and '_'.
+func isAliasRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	case r == '-', r == '_':
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,102 @@
+package services // Package services implements business logic for API keys of machine clients.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"main/internal/constants"
+	"main/internal/models"
+	"strings"
+	"time"
+)
+
+// apiKeyNameMaxLength limits the length of API key names.
+const apiKeyNameMaxLength = 64
+
+// CreateAPIKey issues a new named API key to the current user; the repository assigns the owner from the context.
+// The plain key is returned only here; the repository keeps its hash.
+func (s *UsersService) CreateAPIKey(ctx context.Context, name string) (models.APIKey, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > apiKeyNameMaxLength {
+		return models.APIKey{}, "", fmt.Errorf("%w: must be between 1 and %d characters", ErrInvalidKeyName, apiKeyNameMaxLength)
+	}
+
+	secret, err := NewRandomGenerator(constants.APIKeyLength).Generate()
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return models.APIKey{}, "", fmt.Errorf("failed to generate API key ID: %w", err)
+	}
+
+	key := constants.APIKeyPrefix + secret
+	apiKey := models.APIKey{
+		ID:        id.String(),
+		Name:      name,
+		Prefix:    key[:constants.APIKeyVisibleLength],
+		Hash:      hashToken(key),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.usersRepository.AddAPIKey(ctx, apiKey); err != nil {
+		return models.APIKey{}, "", fmt.Errorf("failed to add API key: %w", err)
+	}
+	return apiKey, key, nil
+}
+
+// GetAPIKeys lists the API keys of the current user.
+func (s *UsersService) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	keys, err := s.usersRepository.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key of the current user.
+func (s *UsersService) RevokeAPIKey(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrAPIKeyNotFound
+	}
+	if err := s.usersRepository.DeleteAPIKey(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateAPIKey resolves the user owning a plain API key.
+// It returns ErrInvalidAPIKey if the key is malformed, unknown or revoked.
+func (s *UsersService) AuthenticateAPIKey(ctx context.Context, key string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if !strings.HasPrefix(key, constants.APIKeyPrefix) {
+		return 0, ErrInvalidAPIKey
+	}
+	userID, err := s.usersRepository.GetUserByAPIKey(ctx, hashToken(key))
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidAPIKey, err)
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to authenticate API key: %w", err)
+	}
+	return userID, nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of an API key or refresh token.
+// Both are long and random, so a fast hash is sufficient to protect them at rest.
+func hashToken(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,141 @@
+package services // Package services implements business logic for click analytics.
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"main/internal/adapters"
+	"main/internal/constants"
+	"main/internal/interfaces"
+	"main/internal/models"
+	"sync/atomic"
+	"time"
+)
+
+// ClicksService encapsulates the business logic for recording and reporting clicks.
+type ClicksService struct {
+	clicksRepository interfaces.ClicksRepository // Dependency for accessing click-related repository methods.
+	queue            chan models.Click           // Buffer of clicks waiting to be stored.
+	ipHashSecret     []byte                      // Secret keying the hashes of client IP addresses.
+	dropped          atomic.Int64                // Number of clicks dropped because the buffer was full.
+}
+
+// NewClicksService constructs a new ClicksService instance bound to a specific clicks repository.
+// Client IP addresses are hashed with the given secret; if it is empty, a random secret is generated,
+// so hashes of the same address differ across restarts.
+func NewClicksService(clicksRepository interfaces.ClicksRepository, ipHashSecret string) (*ClicksService, error) {
+	secret := []byte(ipHashSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, constants.IPHashSecretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate IP hash secret: %w", err)
+		}
+	}
+	return &ClicksService{
+		clicksRepository: clicksRepository,
+		queue:            make(chan models.Click, constants.ClicksQueueSize),
+		ipHashSecret:     secret,
+	}, nil
+}
+
+// Record queues a click for asynchronous storage without blocking the caller.
+// The client IP is hashed before being queued; if the buffer is full, the click is dropped.
+func (s *ClicksService) Record(short, referrer, userAgent, clientIP string) {
+	click := models.Click{
+		Short:     short,
+		ClickedAt: time.Now().UTC(),
+		Referrer:  referrer,
+		UserAgent: userAgent,
+		IPHash:    hashIP(s.ipHashSecret, clientIP),
+	}
+	select {
+	case s.queue <- click:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Run stores queued clicks in batches until the context is canceled, then drains the remaining queue.
+func (s *ClicksService) Run(ctx context.Context) {
+	ticker := time.NewTicker(constants.ClicksFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.Click, 0, constants.ClicksBatchSize)
+	for {
+		select {
+		case click := <-s.queue:
+			batch = append(batch, click)
+			if len(batch) >= constants.ClicksBatchSize {
+				batch = s.flush(batch)
+			}
+		case <-ticker.C:
+			batch = s.flush(batch)
+		case <-ctx.Done():
+			s.drain(batch)
+			return
+		}
+	}
+}
+
+// drain stores the pending batch along with every click remaining in the queue.
+func (s *ClicksService) drain(batch []models.Click) {
+	for {
+		select {
+		case click := <-s.queue:
+			batch = append(batch, click)
+			if len(batch) >= constants.ClicksBatchSize {
+				batch = s.flush(batch)
+			}
+		default:
+			s.flush(batch)
+			return
+		}
+	}
+}
+
+// flush writes a batch of clicks to the repository and returns the emptied batch for reuse.
+func (s *ClicksService) flush(batch []models.Click) []models.Click {
+	logger := adapters.GetLogger()
+
+	if dropped := s.dropped.Swap(0); dropped > 0 {
+		logger.Infow("Clicks dropped due to a full queue", "count", dropped)
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := s.clicksRepository.AddBatch(ctx, batch); err != nil {
+		logger.Infow("Failed to store clicks", "count", len(batch), "error", err.Error())
+	}
+	return batch[:0]
+}
+
+// GetStats retrieves click statistics of a short link owned by the current user.
+func (s *ClicksService) GetStats(ctx context.Context, short string, host string) (models.LinkStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stats, err := s.clicksRepository.GetStats(ctx, short)
+	if err != nil {
+		return models.LinkStats{}, fmt.Errorf("failed to get link stats: %w", err)
+	}
+	stats.Short = getResponseLink(stats.Short, shortPre, constants.URLPrefix+host)
+	return stats, nil
+}
+
+// hashIP returns the hex-encoded HMAC-SHA256 of a client IP address keyed with the secret,
+// so that stored hashes cannot be reversed by hashing the whole address space.
+func hashIP(secret []byte, ip string) string {
+	if ip == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"main/internal/constants"
+	"main/internal/mocks"
+	"main/internal/models"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashIP(t *testing.T) {
+	unsalted := sha256.Sum256([]byte("203.0.113.7"))
+
+	hash := hashIP([]byte("secret"), "203.0.113.7")
+	assert.Len(t, hash, sha256.Size*2)
+	assert.NotEqual(t, hex.EncodeToString(unsalted[:]), hash, "hashes must be keyed")
+	assert.Equal(t, hash, hashIP([]byte("secret"), "203.0.113.7"))
+	assert.NotEqual(t, hash, hashIP([]byte("other"), "203.0.113.7"))
+	assert.Empty(t, hashIP([]byte("secret"), ""))
+}
+
+func TestNewClicksServiceSecret(t *testing.T) {
+	configured, err := NewClicksService(nil, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), configured.ipHashSecret)
+
+	first, err := NewClicksService(nil, "")
+	require.NoError(t, err)
+	second, err := NewClicksService(nil, "")
+	require.NoError(t, err)
+	assert.Len(t, first.ipHashSecret, 32)
+	assert.NotEqual(t, first.ipHashSecret, second.ipHashSecret, "generated secrets must be random")
+}
+
+func TestClicksServiceRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockClicksRepository(ctrl)
+	var stored []models.Click
+	repo.EXPECT().AddBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, clicks []models.Click) error {
+		stored = append(stored, clicks...)
+		return nil
+	}).AnyTimes()
+
+	s, err := NewClicksService(repo, "secret")
+	require.NoError(t, err)
+	s.Record("a", "https://ref.example/", "test", "203.0.113.7")
+	s.Record("b", "", "", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx)
+
+	require.Len(t, stored, 2, "queued clicks are stored when the recorder stops")
+	assert.Equal(t, "a", stored[0].Short)
+	assert.Equal(t, "https://ref.example/", stored[0].Referrer)
+	assert.Equal(t, hashIP([]byte("secret"), "203.0.113.7"), stored[0].IPHash)
+	assert.Empty(t, stored[1].IPHash)
+}
+
+func TestClicksServiceRecordFullQueue(t *testing.T) {
+	s, err := NewClicksService(nil, "secret")
+	require.NoError(t, err)
+
+	for i := 0; i < constants.ClicksQueueSize+3; i++ {
+		s.Record("a", "", "", "")
+	}
+	assert.Len(t, s.queue, constants.ClicksQueueSize)
+	assert.Equal(t, int64(3), s.dropped.Load(), "clicks beyond the queue are dropped instead of blocking")
+}
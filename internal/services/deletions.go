@@ -0,0 +1,124 @@
+package services // Package services implements the background queue of link deletions.
+
+import (
+	"context"
+	"errors"
+	"main/internal/adapters"
+	"main/internal/constants"
+	"main/internal/interfaces"
+	"main/internal/models"
+	"sync"
+	"time"
+)
+
+// ErrDeletionQueueFull is returned when the deletion queue cannot accept more links.
+var ErrDeletionQueueFull = errors.New("deletion queue is full")
+
+// LinksDeleter applies queued link deletions with a pool of workers, batching deletions of different users together.
+type LinksDeleter struct {
+	usersRepository interfaces.UsersRepository // Dependency for applying deletions.
+	queue           chan models.LinkDeletion   // Buffer of deletions waiting to be applied.
+	workers         int                        // Number of workers applying deletions concurrently.
+}
+
+// NewLinksDeleter constructs a new LinksDeleter instance bound to a specific users repository.
+func NewLinksDeleter(usersRepository interfaces.UsersRepository) *LinksDeleter {
+	return &LinksDeleter{
+		usersRepository: usersRepository,
+		queue:           make(chan models.LinkDeletion, constants.DeletionQueueSize),
+		workers:         constants.DeletionWorkers,
+	}
+}
+
+// Enqueue queues links of a user for deletion without blocking the caller.
+// It returns ErrDeletionQueueFull if the queue runs out of space; links queued before that are still deleted.
+func (d *LinksDeleter) Enqueue(userID int64, shortLinks []string) error {
+	for _, short := range shortLinks {
+		select {
+		case d.queue <- models.LinkDeletion{Short: short, UserID: userID}:
+		default:
+			return ErrDeletionQueueFull
+		}
+	}
+	return nil
+}
+
+// Run applies queued deletions with the worker pool until the context is canceled,
+// then drains the remaining queue and returns once every worker has finished.
+func (d *LinksDeleter) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < d.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work collects queued deletions into batches and applies them until the context is canceled.
+func (d *LinksDeleter) work(ctx context.Context) {
+	ticker := time.NewTicker(constants.DeletionFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.LinkDeletion, 0, constants.DeletionBatchSize)
+	for {
+		select {
+		case deletion := <-d.queue:
+			batch = append(batch, deletion)
+			if len(batch) >= constants.DeletionBatchSize {
+				batch = d.flush(batch)
+			}
+		case <-ticker.C:
+			batch = d.flush(batch)
+		case <-ctx.Done():
+			d.drain(batch)
+			return
+		}
+	}
+}
+
+// drain applies the pending batch along with every deletion remaining in the queue.
+func (d *LinksDeleter) drain(batch []models.LinkDeletion) {
+	for {
+		select {
+		case deletion := <-d.queue:
+			batch = append(batch, deletion)
+			if len(batch) >= constants.DeletionBatchSize {
+				batch = d.flush(batch)
+			}
+		default:
+			d.flush(batch)
+			return
+		}
+	}
+}
+
+// flush applies a batch of deletions, retrying failed attempts, and returns the emptied batch for reuse.
+// A batch that keeps failing is dropped and logged.
+func (d *LinksDeleter) flush(batch []models.LinkDeletion) []models.LinkDeletion {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	var err error
+	for attempt := 1; attempt <= constants.DeletionMaxAttempts; attempt++ {
+		if err = d.apply(batch); err == nil {
+			return batch[:0]
+		}
+		if attempt < constants.DeletionMaxAttempts {
+			time.Sleep(time.Duration(attempt) * constants.DeletionRetryDelay)
+		}
+	}
+	adapters.GetLogger().Infow("Failed to delete links", "count", len(batch), "error", err.Error())
+	return batch[:0]
+}
+
+// apply makes a single attempt to store a batch of deletions.
+func (d *LinksDeleter) apply(batch []models.LinkDeletion) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return d.usersRepository.DeleteLinks(ctx, batch)
+}
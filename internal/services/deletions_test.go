@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"main/internal/mocks"
+	"main/internal/models"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinksDeleterDrainsAndRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var mu sync.Mutex
+	var deleted []models.LinkDeletion
+
+	repo := mocks.NewMockUsersRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().DeleteLinks(gomock.Any(), gomock.Any()).Return(errors.New("connection reset")),
+		repo.EXPECT().DeleteLinks(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, batch []models.LinkDeletion) error {
+				mu.Lock()
+				defer mu.Unlock()
+				deleted = append(deleted, batch...)
+				return nil
+			}).AnyTimes(),
+	)
+
+	d := NewLinksDeleter(repo)
+	d.workers = 1
+	require.NoError(t, d.Enqueue(1, []string{"a", "b"}))
+	require.NoError(t, d.Enqueue(2, []string{"c"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.Run(ctx)
+
+	assert.ElementsMatch(t, []models.LinkDeletion{
+		{Short: "a", UserID: 1},
+		{Short: "b", UserID: 1},
+		{Short: "c", UserID: 2},
+	}, deleted)
+}
+
+func TestLinksDeleterQueueFull(t *testing.T) {
+	d := &LinksDeleter{queue: make(chan models.LinkDeletion, 2)}
+
+	assert.NoError(t, d.Enqueue(1, []string{"a"}))
+	assert.ErrorIs(t, d.Enqueue(1, []string{"b", "c"}), ErrDeletionQueueFull)
+	assert.Len(t, d.queue, 2)
+}
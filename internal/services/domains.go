@@ -0,0 +1,210 @@
+package services // Package services implements the domain policy applied to link destinations.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/idna"
+)
+
+// ErrBlockedDomain is returned when the destination of a link is not allowed by the domain policy.
+var ErrBlockedDomain = errors.New("domain is blocked")
+
+// domainPolicyFile represents the structure of the JSON domain policy file, for example:
+//
+//	{
+//	  "blocked": ["malware.example", "*.phishing.example", ".ads.example"],
+//	  "allowed": []
+//	}
+//
+// A pattern is either an exact host name or IP address, a suffix starting with a dot that matches the domain
+// and all of its subdomains, or a wildcard pattern where "*" matches any sequence of characters,
+// so "*.example.com" matches every subdomain of example.com but not example.com itself.
+// If the allowed list is not empty, only matching domains are allowed. Blocked patterns always take precedence.
+type domainPolicyFile struct {
+	Blocked []string `json:"blocked"` // Patterns of domains links may not point to.
+	Allowed []string `json:"allowed"` // Patterns of the only domains links may point to, if any.
+}
+
+// domainRules is a parsed set of domain patterns.
+type domainRules struct {
+	blocked domainPatterns // Patterns of blocked domains.
+	allowed domainPatterns // Patterns of allowed domains; empty if every domain not blocked is allowed.
+}
+
+// domainPatterns groups domain patterns by the way they are matched.
+type domainPatterns struct {
+	exact    map[string]bool // Exact host names.
+	suffixes []string        // Domain suffixes starting with a dot.
+	globs    []string        // Wildcard patterns.
+}
+
+// DomainPolicy decides which domains links may point to according to a blocklist and an allowlist file.
+// The file is reloaded by Reload when it changes, so the rules can be updated without a restart.
+type DomainPolicy struct {
+	path    string                      // Path to the policy file, empty if every domain is allowed.
+	rules   atomic.Pointer[domainRules] // Rules currently in effect.
+	mu      sync.Mutex                  // Serializes reloads.
+	modTime time.Time                   // Modification time of the loaded file.
+	size    int64                       // Size of the loaded file.
+}
+
+// NewDomainPolicy creates a domain policy from the given file. If no file is given, every domain is allowed.
+func NewDomainPolicy(path string) (*DomainPolicy, error) {
+	p := &DomainPolicy{path: path}
+	p.rules.Store(&domainRules{})
+	if path == "" {
+		return p, nil
+	}
+	if _, err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload reads the policy file again if its modification time or size has changed since it was loaded,
+// reporting whether new rules took effect. If the file cannot be read or parsed, the current rules are kept.
+func (p *DomainPolicy) Reload() (bool, error) {
+	if p.path == "" {
+		return false, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read domain policy: %w", err)
+	}
+	if info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return false, nil
+	}
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read domain policy: %w", err)
+	}
+	rules, err := parseDomainPolicy(data)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse domain policy %s: %w", p.path, err)
+	}
+	p.rules.Store(rules)
+	p.modTime, p.size = info.ModTime(), info.Size()
+	return true, nil
+}
+
+// Check returns ErrBlockedDomain if the host of the given URL is not allowed by the policy.
+// URLs whose host cannot be determined are checked as having an empty host.
+func (p *DomainPolicy) Check(rawURL string) error {
+	host := urlHost(rawURL)
+	if !p.rules.Load().allows(host) {
+		return fmt.Errorf("%w: %q", ErrBlockedDomain, host)
+	}
+	return nil
+}
+
+// urlHost returns the lower-case ASCII host of a URL. Origins stored without a scheme by earlier versions,
+// such as "example.com/path", are read as if they had one.
+func urlHost(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "//" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil && net.ParseIP(host) == nil {
+		host = ascii
+	}
+	return host
+}
+
+// allows reports whether links may point to the given host.
+func (r *domainRules) allows(host string) bool {
+	if r.blocked.match(host) {
+		return false
+	}
+	return r.allowed.empty() || r.allowed.match(host)
+}
+
+// parseDomainPolicy parses the contents of a domain policy file.
+func parseDomainPolicy(data []byte) (*domainRules, error) {
+	var file domainPolicyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	blocked, err := newDomainPatterns(file.Blocked)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := newDomainPatterns(file.Allowed)
+	if err != nil {
+		return nil, err
+	}
+	return &domainRules{blocked: blocked, allowed: allowed}, nil
+}
+
+// newDomainPatterns sorts domain patterns by the way they are matched, converting internationalized names to punycode.
+func newDomainPatterns(patterns []string) (domainPatterns, error) {
+	result := domainPatterns{exact: make(map[string]bool)}
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+		switch {
+		case pattern == "":
+			continue
+		case strings.Contains(pattern, "*"):
+			if _, err := path.Match(pattern, ""); err != nil {
+				return result, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			result.globs = append(result.globs, pattern)
+		case strings.HasPrefix(pattern, "."):
+			domain, err := idna.Lookup.ToASCII(pattern[1:])
+			if err != nil {
+				return result, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			result.suffixes = append(result.suffixes, "."+domain)
+		case net.ParseIP(pattern) != nil:
+			result.exact[pattern] = true
+		default:
+			domain, err := idna.Lookup.ToASCII(pattern)
+			if err != nil {
+				return result, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			result.exact[domain] = true
+		}
+	}
+	return result, nil
+}
+
+// empty reports whether there are no patterns.
+func (p domainPatterns) empty() bool {
+	return len(p.exact) == 0 && len(p.suffixes) == 0 && len(p.globs) == 0
+}
+
+// match reports whether the host matches any of the patterns.
+func (p domainPatterns) match(host string) bool {
+	if p.exact[host] {
+		return true
+	}
+	for _, suffix := range p.suffixes {
+		if host == suffix[1:] || strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	for _, glob := range p.globs {
+		if ok, _ := path.Match(glob, host); ok {
+			return true
+		}
+	}
+	return false
+}
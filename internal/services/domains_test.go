@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomainPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		url     string
+		blocked bool
+	}{
+		{name: "no rules", policy: `{}`, url: "https://example.com/"},
+		{name: "exact", policy: `{"blocked": ["example.com"]}`, url: "https://example.com/", blocked: true},
+		{name: "exact subdomain", policy: `{"blocked": ["example.com"]}`, url: "https://www.example.com/"},
+		{name: "exact case and trailing dot", policy: `{"blocked": ["Example.COM."]}`, url: "https://example.com/", blocked: true},
+		{name: "wildcard subdomain", policy: `{"blocked": ["*.example.com"]}`, url: "https://a.b.example.com/", blocked: true},
+		{name: "wildcard apex", policy: `{"blocked": ["*.example.com"]}`, url: "https://example.com/"},
+		{name: "wildcard label", policy: `{"blocked": ["ads-*.example.com"]}`, url: "https://ads-1.example.com/", blocked: true},
+		{name: "suffix apex", policy: `{"blocked": [".example.com"]}`, url: "https://example.com/", blocked: true},
+		{name: "suffix subdomain", policy: `{"blocked": [".example.com"]}`, url: "https://www.example.com/", blocked: true},
+		{name: "suffix lookalike", policy: `{"blocked": [".example.com"]}`, url: "https://notexample.com/"},
+		{name: "idn pattern", policy: `{"blocked": ["bücher.example"]}`, url: "https://xn--bcher-kva.example/", blocked: true},
+		{name: "ip address", policy: `{"blocked": ["::1"]}`, url: "http://[::1]/", blocked: true},
+		{name: "allowlist match", policy: `{"allowed": [".example.com"]}`, url: "https://go.example.com/"},
+		{name: "allowlist miss", policy: `{"allowed": [".example.com"]}`, url: "https://example.org/", blocked: true},
+		{name: "scheme-less origin", policy: `{"blocked": ["example.com"]}`, url: "example.com/path", blocked: true},
+		{name: "scheme-less origin allowed", policy: `{"allowed": ["example.com"]}`, url: "example.com/path"},
+		{name: "malformed origin", policy: `{"allowed": ["example.com"]}`, url: "http://exa mple.com/%zz", blocked: true},
+		{name: "idn origin", policy: `{"blocked": ["xn--bcher-kva.example"]}`, url: "bücher.example/", blocked: true},
+		{name: "blocklist wins", policy: `{"allowed": [".example.com"], "blocked": ["bad.example.com"]}`, url: "https://bad.example.com/", blocked: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "domains.json")
+			require.NoError(t, os.WriteFile(path, []byte(test.policy), 0o600))
+
+			policy, err := NewDomainPolicy(path)
+			require.NoError(t, err)
+
+			err = policy.Check(test.url)
+			assert.Equal(t, test.blocked, errors.Is(err, ErrBlockedDomain), "error: %v", err)
+		})
+	}
+}
+
+func TestDomainPolicyReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "domains.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"blocked": ["a.example"]}`), 0o600))
+
+	policy, err := NewDomainPolicy(path)
+	require.NoError(t, err)
+
+	reloaded, err := policy.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded, "unchanged file must not be reloaded")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"blocked": ["b.example"]}`), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+	reloaded, err = policy.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.NoError(t, policy.Check("https://a.example/"))
+	assert.ErrorIs(t, policy.Check("https://b.example/"), ErrBlockedDomain)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"blocked": [`), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second)))
+
+	_, err = policy.Reload()
+	assert.Error(t, err)
+	assert.ErrorIs(t, policy.Check("https://b.example/"), ErrBlockedDomain, "invalid file must keep the previous rules")
+}
+
+func TestNewDomainPolicyErrors(t *testing.T) {
+	_, err := NewDomainPolicy(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "domains.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"blocked": ["[a.example"]}`), 0o600))
+	_, err = NewDomainPolicy(path)
+	assert.Error(t, err)
+
+	policy, err := NewDomainPolicy("")
+	require.NoError(t, err)
+	assert.NoError(t, policy.Check("https://example.com/"))
+}
@@ -0,0 +1,160 @@
+package services // Package services provides strategies for generating short link codes.
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"main/internal/interfaces"
+	"math"
+	"strings"
+	"sync/atomic"
+)
+
+// Names of the supported short code generation strategies.
+const (
+	RandomStrategy  = "random"  // Uniformly random base62 strings.
+	CounterStrategy = "counter" // Base62-encoded monotonic counter.
+	UUIDStrategy    = "uuid"    // Full UUID strings, kept for backward compatibility.
+)
+
+// Limits for the configurable short code length.
+const (
+	minShortCodeLength = 4
+	maxShortCodeLength = 64
+)
+
+// base62Alphabet holds the characters used for encoding short codes.
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ErrUnknownStrategy is returned when the configured short code strategy is not supported.
+var ErrUnknownStrategy = errors.New("unknown short code strategy")
+
+// NewShortCodeGenerator constructs a generator for the given strategy and code length.
+func NewShortCodeGenerator(strategy string, length int) (interfaces.ShortCodeGenerator, error) {
+	if strategy == UUIDStrategy {
+		return &UUIDGenerator{}, nil
+	}
+	if length < minShortCodeLength || length > maxShortCodeLength {
+		return nil, fmt.Errorf("short code length must be between %d and %d, got %d", minShortCodeLength, maxShortCodeLength, length)
+	}
+	switch strategy {
+	case RandomStrategy:
+		return NewRandomGenerator(length), nil
+	case CounterStrategy:
+		return NewCounterGenerator(length)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStrategy, strategy)
+	}
+}
+
+// RandomGenerator produces uniformly distributed base62 codes of a fixed length.
+type RandomGenerator struct {
+	length int // Number of characters in each generated code.
+}
+
+// NewRandomGenerator constructs a RandomGenerator producing codes of the given length.
+func NewRandomGenerator(length int) *RandomGenerator {
+	return &RandomGenerator{
+		length: length,
+	}
+}
+
+// Generate returns a new random base62 code.
+// Bytes outside the largest multiple of 62 are rejected to avoid modulo bias.
+func (g *RandomGenerator) Generate() (string, error) {
+	const limit = 256 - 256%len(base62Alphabet)
+
+	var sb strings.Builder
+	sb.Grow(g.length)
+
+	buf := make([]byte, g.length*2)
+	for sb.Len() < g.length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			sb.WriteByte(base62Alphabet[int(b)%len(base62Alphabet)])
+			if sb.Len() == g.length {
+				break
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// CounterGenerator produces base62-encoded values of a monotonic counter, left-padded to a fixed length.
+// The counter starts from a random offset so that restarts do not replay previously issued codes.
+type CounterGenerator struct {
+	length  int           // Number of characters in each generated code.
+	space   uint64        // Number of distinct codes of the given length, zero if it exceeds uint64.
+	counter atomic.Uint64 // Current counter value.
+}
+
+// NewCounterGenerator constructs a CounterGenerator producing codes of the given length.
+func NewCounterGenerator(length int) (*CounterGenerator, error) {
+	g := &CounterGenerator{
+		length: length,
+		space:  codeSpace(length),
+	}
+
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, fmt.Errorf("failed to seed counter: %w", err)
+	}
+	g.counter.Store(binary.BigEndian.Uint64(seed[:]))
+	return g, nil
+}
+
+// Generate returns the next counter value encoded in base62.
+func (g *CounterGenerator) Generate() (string, error) {
+	n := g.counter.Add(1)
+	if g.space != 0 {
+		n %= g.space
+	}
+	return encodeBase62(n, g.length), nil
+}
+
+// UUIDGenerator produces random UUID strings.
+type UUIDGenerator struct{}
+
+// Generate returns a new random UUID string.
+func (g *UUIDGenerator) Generate() (string, error) {
+	u, err := uuid.NewRandom()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate UUID: %w", err)
+	}
+	return u.String(), nil
+}
+
+// encodeBase62 converts a number to base62, left-padding the result with zeros up to the given length.
+func encodeBase62(n uint64, length int) string {
+	buf := make([]byte, 0, length)
+	for n > 0 {
+		buf = append(buf, base62Alphabet[n%uint64(len(base62Alphabet))])
+		n /= uint64(len(base62Alphabet))
+	}
+	for len(buf) < length {
+		buf = append(buf, base62Alphabet[0])
+	}
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf)
+}
+
+// codeSpace returns 62^length, or zero if the value does not fit into uint64.
+func codeSpace(length int) uint64 {
+	space := uint64(1)
+	for i := 0; i < length; i++ {
+		if space > math.MaxUint64/uint64(len(base62Alphabet)) {
+			return 0
+		}
+		space *= uint64(len(base62Alphabet))
+	}
+	return space
+}
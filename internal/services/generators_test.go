@@ -0,0 +1,62 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewShortCodeGenerator(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		length   int
+		wantErr  bool
+	}{
+		{name: "random", strategy: RandomStrategy, length: 8},
+		{name: "counter", strategy: CounterStrategy, length: 6},
+		{name: "uuid ignores length", strategy: UUIDStrategy, length: 0},
+		{name: "unknown strategy", strategy: "sequence", length: 8, wantErr: true},
+		{name: "too short", strategy: RandomStrategy, length: 2, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := NewShortCodeGenerator(test.strategy, test.length)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			code, err := g.Generate()
+			require.NoError(t, err)
+			if test.strategy != UUIDStrategy {
+				assert.Len(t, code, test.length)
+				for _, r := range code {
+					assert.True(t, strings.ContainsRune(base62Alphabet, r), "unexpected rune %q", r)
+				}
+			}
+		})
+	}
+}
+
+func TestCounterGeneratorUnique(t *testing.T) {
+	g, err := NewCounterGenerator(4)
+	require.NoError(t, err)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		code, err := g.Generate()
+		require.NoError(t, err)
+		assert.False(t, seen[code], "duplicate code %s", code)
+		seen[code] = true
+	}
+}
+
+func TestEncodeBase62(t *testing.T) {
+	assert.Equal(t, "0000", encodeBase62(0, 4))
+	assert.Equal(t, "000z", encodeBase62(61, 4))
+	assert.Equal(t, "0010", encodeBase62(62, 4))
+}
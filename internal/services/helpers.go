@@ -1,22 +1,25 @@
 package services // Package services provides helper functions for generating keys and URLs.
 
 import (
-	"github.com/google/uuid"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"main/internal/constants"
+	"main/internal/models"
 	"net/url"
 )
 
 // shortPre represents a configurable prefix for generated short links.
 var shortPre string
 
-// getKey generates a unique key for a given UUID and prefix.
-// If the prefix is a valid URL, the key includes only the UUID.
-// Otherwise, the key combines the prefix and UUID.
-func getKey(u uuid.UUID, p string) string {
+// getKey generates a unique key for a given short code and prefix.
+// If the prefix is a valid URL, the key includes only the code.
+// Otherwise, the key combines the prefix and code.
+func getKey(code string, p string) string {
 	if isURL(p) {
-		return u.String()
+		return code
 	}
-	return p + u.String()
+	return p + code
 }
 
 // getResponseLink constructs a full response URL combining the key, prefix, and host.
@@ -33,3 +36,36 @@ func isURL(str string) bool {
 	u, err := url.Parse(str)
 	return err == nil && u.Scheme != "" && u.Host != ""
 }
+
+// encodeLinksCursor serializes the position of a link in a listing into an opaque URL-safe cursor.
+func encodeLinksCursor(cursor models.LinksCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeLinksCursor restores the position of a link in a listing from a cursor made by encodeLinksCursor.
+func decodeLinksCursor(cursor string) (models.LinksCursor, error) {
+	var decoded models.LinksCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return decoded, err
+	}
+	err = json.Unmarshal(data, &decoded)
+	return decoded, err
+}
+
+// linksFiltersHash summarizes the filters of a links query, so that a cursor can only continue the listing it came from.
+func linksFiltersHash(query models.LinksQuery) string {
+	data, _ := json.Marshal(struct {
+		Origin       string `json:"o"`
+		CreatedAfter int64  `json:"t"`
+		Deleted      bool   `json:"d"`
+	}{
+		Origin:       query.Origin,
+		CreatedAfter: query.CreatedAfter.UnixNano(),
+		Deleted:      query.Deleted,
+	})
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
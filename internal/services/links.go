@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/google/uuid"
 	"main/internal/config"
 	"main/internal/constants"
 	"main/internal/interfaces"
@@ -12,41 +11,93 @@ import (
 	"time"
 )
 
-// Custom error types for handling link conflicts and deleted links.
+// Custom error types for handling link conflicts, deleted or expired links, custom aliases and invalid URLs.
+// Links to domains rejected by the domain policy are reported with ErrBlockedDomain.
 var (
-	ErrConflict    = errors.New("data conflict")
-	ErrDeletedLink = errors.New("link is deleted")
+	ErrConflict          = errors.New("data conflict")
+	ErrDeletedLink       = errors.New("link is deleted")
+	ErrExpiredLink       = errors.New("link is expired")
+	ErrInvalidAlias      = errors.New("invalid alias")
+	ErrInvalidExpiration = errors.New("invalid expiration")
+	ErrInvalidURL        = errors.New("invalid original url")
+	ErrLinkNotFound      = errors.New("link not found")
+	ErrShortLinkTaken    = errors.New("short link is already taken")
 )
 
+// maxGenerateAttempts limits how many times a colliding short code is regenerated.
+const maxGenerateAttempts = 5
+
 // LinksService encapsulates the business logic for link management.
 type LinksService struct {
-	linksRepository interfaces.LinksRepository // Dependency for accessing link-related repository methods.
+	linksRepository  interfaces.LinksRepository    // Dependency for accessing link-related repository methods.
+	generator        interfaces.ShortCodeGenerator // Strategy used to produce short codes.
+	deletedRetention time.Duration                 // Time soft-deleted links can be restored before being purged.
+	domains          interfaces.DomainPolicy       // Policy deciding which domains links may point to.
 }
 
-// NewLinksService constructs a new LinksService instance wired to a specific links repository.
-func NewLinksService(c *config.Config, linksRepository interfaces.LinksRepository) *LinksService {
+// NewLinksService constructs a new LinksService instance wired to a specific links repository and domain policy.
+// The short code strategy and length and the retention of deleted links are taken from the configuration.
+func NewLinksService(c *config.Config, linksRepository interfaces.LinksRepository, domains interfaces.DomainPolicy) (*LinksService, error) {
 	shortPre = c.ShortLinkPrefix
-	return &LinksService{
-		linksRepository: linksRepository,
+
+	generator, err := NewShortCodeGenerator(c.ShortCodeStrategy, c.ShortCodeLength)
+	if err != nil {
+		return nil, err
 	}
+	return &LinksService{
+		linksRepository:  linksRepository,
+		generator:        generator,
+		deletedRetention: c.DeletedRetention,
+		domains:          domains,
+	}, nil
 }
 
 // Add creates a new link record, assigning a unique short identifier.
+// The original URL is validated and normalized first, so equivalent URLs share a single short link.
+// Links to domains rejected by the domain policy are not created.
+// If the origin link carries a custom alias, it is validated and used as the short identifier as is.
+// Generated identifiers that collide with existing ones are regenerated a limited number of times.
 func (s *LinksService) Add(ctx context.Context, originLink models.OriginLink, host string) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	u, err := uuid.NewRandom()
+	originURL, err := normalizeURL(originLink.URL)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate UUID: %w", err)
+		return "", err
 	}
-
-	addedLink := models.AddedLink{
-		Short:  getKey(u, shortPre),
-		Origin: originLink.URL,
+	if err := s.domains.Check(originURL); err != nil {
+		return "", err
+	}
+	if originLink.Alias != "" {
+		if err := validateAlias(originLink.Alias); err != nil {
+			return "", err
+		}
 	}
+	expiresAt, err := expirationTime(originLink)
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		addedLink := models.AddedLink{
+			Short:     originLink.Alias,
+			Origin:    originURL,
+			ExpiresAt: expiresAt,
+		}
+		if addedLink.Short == "" {
+			addedLink.Short, err = s.generateKey()
+			if err != nil {
+				return "", err
+			}
+		}
 
-	id, err := s.linksRepository.Add(ctx, addedLink)
+		id, err = s.linksRepository.Add(ctx, addedLink)
+		if !errors.Is(err, ErrShortLinkTaken) || originLink.Alias != "" {
+			break
+		}
+	}
 	if err != nil {
 		if errors.Is(err, ErrConflict) {
 			return getResponseLink(id, shortPre, constants.URLPrefix+host), err
@@ -58,49 +109,208 @@ func (s *LinksService) Add(ctx context.Context, originLink models.OriginLink, ho
 }
 
 // AddBatch allows batch-adding multiple links simultaneously.
+// Every item gets its own status: created for new links, exists for already shortened origins
+// and invalid for rejected items, including links to blocked domains, so a single bad item does not fail the whole batch.
+// If any generated identifier collides with an existing one, the valid items are regenerated and retried.
 func (s *LinksService) AddBatch(ctx context.Context, originLinks []models.OriginLink, host string) ([]models.Result, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	retries := 0
-	var addedLinks []models.AddedLink
+	responseLinks := make([]models.Result, len(originLinks))
 
-	for i := 0; i < len(originLinks); {
-		u, err := uuid.NewRandom()
+	var validLinks []models.OriginLink
+	var validIndexes []int
+
+	for i, originLink := range originLinks {
+		responseLinks[i].CorrelationID = originLink.CorrelationID
+		originLink, err := s.normalizeOriginLink(originLink)
 		if err != nil {
-			retries += 1
+			responseLinks[i].Result, responseLinks[i].Status = err.Error(), constants.LinkInvalid
 			continue
 		}
-		if retries >= 5 {
-			return nil, fmt.Errorf("failed to generate UUIDs: %w", err)
+		validLinks = append(validLinks, originLink)
+		validIndexes = append(validIndexes, i)
+	}
+	if len(validLinks) == 0 {
+		return responseLinks, nil
+	}
+
+	var results []models.Result
+	var err error
+
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		var addedLinks []models.AddedLink
+		addedLinks, err = s.newAddedLinks(validLinks)
+		if err != nil {
+			return nil, err
 		}
-		addedLink := models.AddedLink{
-			CorrelationID: originLinks[i].CorrelationID,
-			Short:         getKey(u, shortPre),
-			Origin:        originLinks[i].URL,
+
+		results, err = s.linksRepository.AddBatch(ctx, addedLinks)
+		if !errors.Is(err, ErrShortLinkTaken) {
+			break
 		}
-		addedLinks = append(addedLinks, addedLink)
-		i++
 	}
-
-	results, err := s.linksRepository.AddBatch(ctx, addedLinks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add links: %w", err)
 	}
 
-	var responseLinks []models.Result
+	for i, result := range results {
+		responseLinks[validIndexes[i]] = models.Result{
+			CorrelationID: result.CorrelationID,
+			Result:        getResponseLink(result.Result, shortPre, constants.URLPrefix+host),
+			Status:        result.Status,
+		}
+	}
+	return responseLinks, nil
+}
+
+// Import adds links migrated from another shortener, preserving the short codes carried as aliases when they are free.
+// Every item gets its own status: created for links added under the requested or a generated code, renamed
+// for links whose requested code was taken and got a generated one instead, exists for already shortened origins
+// and invalid for rejected items, including links to blocked domains.
+func (s *LinksService) Import(ctx context.Context, originLinks []models.OriginLink, host string) ([]models.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	responseLinks := make([]models.Result, len(originLinks))
+
+	var addedLinks []models.AddedLink
+	var addedIndexes []int
+
+	for i, originLink := range originLinks {
+		responseLinks[i].CorrelationID = originLink.CorrelationID
+		originLink, err := s.normalizeOriginLink(originLink)
+		if err == nil && originLink.Alias != "" {
+			err = validateAlias(originLink.Alias)
+		}
+		if err != nil {
+			responseLinks[i].Result, responseLinks[i].Status = err.Error(), constants.LinkInvalid
+			continue
+		}
+
+		short := originLink.Alias
+		if short == "" {
+			if short, err = s.generateKey(); err != nil {
+				return nil, err
+			}
+		}
+		expiresAt, _ := expirationTime(originLink)
+
+		addedLinks = append(addedLinks, models.AddedLink{
+			CorrelationID: originLink.CorrelationID,
+			Short:         short,
+			Origin:        originLink.URL,
+			ExpiresAt:     expiresAt,
+		})
+		addedIndexes = append(addedIndexes, i)
+	}
+	if len(addedLinks) == 0 {
+		return responseLinks, nil
+	}
+
+	results, err := s.linksRepository.Import(ctx, addedLinks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import links: %w", err)
+	}
+
+	var takenLinks []models.OriginLink
+	var takenIndexes []int
 
-	for _, result := range results {
-		response := models.Result{
+	for i, result := range results {
+		index := addedIndexes[i]
+		if result.Status == constants.LinkTaken {
+			takenLinks = append(takenLinks, models.OriginLink{
+				CorrelationID: originLinks[index].CorrelationID,
+				URL:           addedLinks[i].Origin,
+				Alias:         originLinks[index].Alias,
+				ExpiresAt:     addedLinks[i].ExpiresAt,
+			})
+			takenIndexes = append(takenIndexes, index)
+			continue
+		}
+		responseLinks[index] = models.Result{
 			CorrelationID: result.CorrelationID,
 			Result:        getResponseLink(result.Result, shortPre, constants.URLPrefix+host),
+			Status:        result.Status,
 		}
-		responseLinks = append(responseLinks, response)
+	}
+	if len(takenLinks) == 0 {
+		return responseLinks, nil
+	}
+
+	renamed, err := s.AddBatch(ctx, takenLinks, host)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range renamed {
+		if result.Status == constants.LinkCreated && takenLinks[i].Alias != "" {
+			result.Status = constants.LinkRenamed
+		}
+		responseLinks[takenIndexes[i]] = result
 	}
 	return responseLinks, nil
 }
 
+// normalizeOriginLink checks that a batch item has a valid URL to an allowed domain and a valid expiration
+// and returns it with the URL normalized.
+func (s *LinksService) normalizeOriginLink(originLink models.OriginLink) (models.OriginLink, error) {
+	originURL, err := normalizeURL(originLink.URL)
+	if err != nil {
+		return originLink, err
+	}
+	if err := s.domains.Check(originURL); err != nil {
+		return originLink, err
+	}
+	if _, err := expirationTime(originLink); err != nil {
+		return originLink, err
+	}
+	originLink.URL = originURL
+	return originLink, nil
+}
+
+// newAddedLinks assigns distinct generated short identifiers to a batch of origin links.
+func (s *LinksService) newAddedLinks(originLinks []models.OriginLink) ([]models.AddedLink, error) {
+	addedLinks := make([]models.AddedLink, 0, len(originLinks))
+	seen := make(map[string]bool, len(originLinks))
+
+	for i := 0; i < len(originLinks); {
+		short, err := s.generateKey()
+		if err != nil {
+			return nil, err
+		}
+		if seen[short] {
+			continue
+		}
+		seen[short] = true
+
+		expiresAt, err := expirationTime(originLinks[i])
+		if err != nil {
+			return nil, err
+		}
+
+		addedLink := models.AddedLink{
+			CorrelationID: originLinks[i].CorrelationID,
+			Short:         short,
+			Origin:        originLinks[i].URL,
+			ExpiresAt:     expiresAt,
+		}
+		addedLinks = append(addedLinks, addedLink)
+		i++
+	}
+	return addedLinks, nil
+}
+
+// generateKey produces a new short identifier using the configured generator and prefix.
+func (s *LinksService) generateKey() (string, error) {
+	code, err := s.generator.Generate()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate short code: %w", err)
+	}
+	return getKey(code, shortPre), nil
+}
+
 // Get resolves a short link to its original URL.
+// The domain policy is consulted again, so links to domains blocked after they were created are not followed.
 func (s *LinksService) Get(ctx context.Context, shortLink string) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
@@ -109,5 +319,74 @@ func (s *LinksService) Get(ctx context.Context, shortLink string) (string, error
 	if err != nil {
 		return "", fmt.Errorf("origin link not found: %w", err)
 	}
+	if err := s.domains.Check(originLink); err != nil {
+		return "", err
+	}
 	return originLink, nil
 }
+
+// Update changes the destination of a short link owned by the current user.
+// The new destination is validated, normalized and checked against the domain policy like a new link, and the previous one is kept
+// in the link history by the repository.
+func (s *LinksService) Update(ctx context.Context, shortLink, originLink string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	originURL, err := normalizeURL(originLink)
+	if err != nil {
+		return err
+	}
+	if err := s.domains.Check(originURL); err != nil {
+		return err
+	}
+	if err := s.linksRepository.Update(ctx, shortLink, originURL); err != nil {
+		if errors.Is(err, ErrLinkNotFound) || errors.Is(err, ErrConflict) {
+			return err
+		}
+		return fmt.Errorf("failed to update link: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpired removes links whose expiry is older than the configured retention period.
+func (s *LinksService) PurgeExpired(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	deleted, err := s.linksRepository.DeleteExpired(ctx, time.Now().Add(-constants.ExpiredLinksRetention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired links: %w", err)
+	}
+	return deleted, nil
+}
+
+// PurgeDeleted permanently removes links soft-deleted longer than the configured retention period.
+// Until then, deleted links can still be restored by their owners.
+func (s *LinksService) PurgeDeleted(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	purged, err := s.linksRepository.DeleteSoftDeleted(ctx, time.Now().Add(-s.deletedRetention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted links: %w", err)
+	}
+	return purged, nil
+}
+
+// expirationTime resolves the moment a link expires from either its explicit expiry or its TTL.
+// A zero time means the link never expires.
+func expirationTime(originLink models.OriginLink) (time.Time, error) {
+	if !originLink.ExpiresAt.IsZero() {
+		if !originLink.ExpiresAt.After(time.Now()) {
+			return time.Time{}, fmt.Errorf("%w: expires_at is in the past", ErrInvalidExpiration)
+		}
+		return originLink.ExpiresAt, nil
+	}
+	if originLink.TTL < 0 {
+		return time.Time{}, fmt.Errorf("%w: ttl must be positive", ErrInvalidExpiration)
+	}
+	if originLink.TTL > 0 {
+		return time.Now().Add(originLink.TTL), nil
+	}
+	return time.Time{}, nil
+}
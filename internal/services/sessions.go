@@ -0,0 +1,53 @@
+package services // Package services implements business logic for server-side user sessions.
+
+import (
+	"context"
+	"fmt"
+	"main/internal/constants"
+	"main/internal/models"
+	"time"
+)
+
+// IssueRefreshToken starts a server-side session for the user and returns its plain refresh token.
+func (s *UsersService) IssueRefreshToken(ctx context.Context, userID int64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	token, err := NewRandomGenerator(constants.RefreshTokenLength).Generate()
+	if err != nil {
+		return "", err
+	}
+	refreshToken := models.RefreshToken{
+		Hash:      hashToken(token),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(constants.RefreshTokenExp).UTC(),
+	}
+	if err := s.usersRepository.AddRefreshToken(ctx, refreshToken); err != nil {
+		return "", fmt.Errorf("failed to add refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// RefreshSession extends the session of a refresh token by another expiration period and returns its user.
+// It returns ErrSessionNotFound if the token is unknown, revoked or expired.
+func (s *UsersService) RefreshSession(ctx context.Context, token string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	userID, err := s.usersRepository.ExtendRefreshToken(ctx, hashToken(token), time.Now().Add(constants.RefreshTokenExp).UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh session: %w", err)
+	}
+	return userID, nil
+}
+
+// Logout revokes the session of a refresh token. Unknown tokens are ignored.
+func (s *UsersService) Logout(ctx context.Context, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := s.usersRepository.DeleteRefreshToken(ctx, hashToken(token)); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
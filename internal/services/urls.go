@@ -0,0 +1,96 @@
+package services // Package services provides validation and normalization of original URLs.
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Length limits for original URLs.
+const (
+	urlMaxLength  = 2048
+	hostMaxLength = 253
+)
+
+// allowedSchemes lists the schemes original URLs may use, along with their default ports.
+var allowedSchemes = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// normalizeURL validates an original URL and brings it to a canonical form, so that equivalent URLs are stored once:
+// the scheme and host are lowercased, internationalized host names are converted to punycode,
+// default ports are dropped and an empty path becomes "/".
+func normalizeURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", fmt.Errorf("%w: url is empty", ErrInvalidURL)
+	}
+	if len(rawURL) > urlMaxLength {
+		return "", fmt.Errorf("%w: url is longer than %d characters", ErrInvalidURL, urlMaxLength)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed url", ErrInvalidURL)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	defaultPort, ok := allowedSchemes[u.Scheme]
+	if !ok {
+		return "", fmt.Errorf("%w: url must be absolute with the http or https scheme", ErrInvalidURL)
+	}
+	if u.Opaque != "" || u.Host == "" {
+		return "", fmt.Errorf("%w: url has no host", ErrInvalidURL)
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return "", err
+	}
+	port := u.Port()
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return "", fmt.Errorf("%w: invalid port %q", ErrInvalidURL, port)
+		}
+	}
+	if port == defaultPort {
+		port = ""
+	}
+	u.Host = host
+	if port != "" {
+		u.Host += ":" + port
+	}
+	if u.Path == "" {
+		u.Path, u.RawPath = "/", ""
+	}
+
+	normalized := u.String()
+	if len(normalized) > urlMaxLength {
+		return "", fmt.Errorf("%w: url is longer than %d characters", ErrInvalidURL, urlMaxLength)
+	}
+	return normalized, nil
+}
+
+// normalizeHost lowercases a host name and converts it to its ASCII form, keeping IP addresses as they are.
+// IPv6 addresses are returned in brackets, ready to be used in a URL.
+func normalizeHost(host string) (string, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() == nil {
+			return "[" + strings.ToLower(host) + "]", nil
+		}
+		return host, nil
+	}
+
+	ascii, err := idna.Lookup.ToASCII(strings.TrimSuffix(host, "."))
+	if err != nil || ascii == "" {
+		return "", fmt.Errorf("%w: invalid host %q", ErrInvalidURL, host)
+	}
+	if len(ascii) > hostMaxLength {
+		return "", fmt.Errorf("%w: host is longer than %d characters", ErrInvalidURL, hostMaxLength)
+	}
+	return ascii, nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawURL  string
+		want    string
+		wantErr bool
+	}{
+		{name: "already normal", rawURL: "https://go.dev/blog/", want: "https://go.dev/blog/"},
+		{name: "scheme and host case", rawURL: "HTTP://Example.COM/Path", want: "http://example.com/Path"},
+		{name: "empty path", rawURL: "http://example.com", want: "http://example.com/"},
+		{name: "surrounding spaces", rawURL: "  https://example.com/a?b=c#d \n", want: "https://example.com/a?b=c#d"},
+		{name: "default http port", rawURL: "http://example.com:80/", want: "http://example.com/"},
+		{name: "default https port", rawURL: "https://example.com:443/x", want: "https://example.com/x"},
+		{name: "custom port", rawURL: "https://example.com:8443/x", want: "https://example.com:8443/x"},
+		{name: "idn host", rawURL: "https://Bücher.example/", want: "https://xn--bcher-kva.example/"},
+		{name: "trailing dot", rawURL: "https://example.com./", want: "https://example.com/"},
+		{name: "ipv4", rawURL: "http://127.0.0.1:8080", want: "http://127.0.0.1:8080/"},
+		{name: "ipv6", rawURL: "http://[::1]:80/x", want: "http://[::1]/x"},
+		{name: "empty", rawURL: " ", wantErr: true},
+		{name: "javascript", rawURL: "javascript:alert(1)", wantErr: true},
+		{name: "ftp", rawURL: "ftp://example.com/file", wantErr: true},
+		{name: "relative path", rawURL: "/some/path", wantErr: true},
+		{name: "no scheme", rawURL: "example.com/path", wantErr: true},
+		{name: "no host", rawURL: "http:///path", wantErr: true},
+		{name: "port out of range", rawURL: "http://example.com:70000/", wantErr: true},
+		{name: "invalid host", rawURL: "http://exa mple.com/", wantErr: true},
+		{name: "too long", rawURL: "https://example.com/" + strings.Repeat("a", urlMaxLength), wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := normalizeURL(test.rawURL)
+			if test.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidURL)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
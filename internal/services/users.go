@@ -7,28 +7,37 @@ import (
 	"main/internal/constants"
 	"main/internal/interfaces"
 	"main/internal/models"
-	"sync"
 	"time"
 )
 
-// Constants for batch-processing of link deletions.
-const batchSize = 5
-
 // Custom error types for user-related failures.
 var (
-	ErrAddUser       = errors.New("failed to insert user")
-	ErrNoLinksByUser = errors.New("links by userID %d not found")
+	ErrAddUser         = errors.New("failed to insert user")
+	ErrNoLinksByUser   = errors.New("links by userID %d not found")
+	ErrAPIKeyNotFound  = errors.New("api key not found")
+	ErrInvalidAPIKey   = errors.New("invalid api key")
+	ErrInvalidKeyName  = errors.New("invalid api key name")
+	ErrSessionNotFound = errors.New("session not found or expired")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrUsernameTaken   = errors.New("username is already taken")
+	ErrInvalidUsername = errors.New("invalid username")
+	ErrInvalidPassword = errors.New("invalid password")
+	ErrBadCredentials  = errors.New("invalid username or password")
+	ErrInvalidQuery    = errors.New("invalid links query")
+	ErrInvalidCursor   = errors.New("invalid cursor")
 )
 
 // UsersService encapsulates the business logic for user management.
 type UsersService struct {
 	usersRepository interfaces.UsersRepository // Dependency for accessing user-related repository methods.
+	deleter         interfaces.LinksDeleter    // Background queue applying link deletions.
 }
 
-// NewUserService constructs a new UsersService instance bound to a specific users repository.
-func NewUserService(usersRepository interfaces.UsersRepository) *UsersService {
+// NewUserService constructs a new UsersService instance bound to a specific users repository and deletion queue.
+func NewUserService(usersRepository interfaces.UsersRepository, deleter interfaces.LinksDeleter) *UsersService {
 	return &UsersService{
 		usersRepository: usersRepository,
+		deleter:         deleter,
 	}
 }
 
@@ -44,95 +53,95 @@ func (s *UsersService) Login() (int64, error) {
 	return userID, nil
 }
 
-// GetLinks retrieves all links created by the current user.
-func (s *UsersService) GetLinks(ctx context.Context, host string) ([]models.UserLinks, error) {
+// GetLinks retrieves a page of the links created by the current user that match the query, sorted by creation time
+// unless another sort key is requested. The cursor returned by the previous call continues the listing, and
+// the returned cursor is empty once the last page has been reached.
+func (s *UsersService) GetLinks(ctx context.Context, host string, query models.LinksQuery, cursor string) ([]models.UserLinks, string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	var links []models.UserLinks
-
-	results, err := s.usersRepository.GetLinks(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("links not found: %w", err)
+	if query.SortBy == "" {
+		query.SortBy = constants.LinksSortCreated
 	}
-	for _, result := range results {
-		link := models.UserLinks{
-			Shorten:  getResponseLink(result.Shorten, shortPre, constants.URLPrefix+host),
-			Original: result.Original,
+	if query.SortBy != constants.LinksSortCreated && query.SortBy != constants.LinksSortClicks {
+		return nil, "", ErrInvalidQuery
+	}
+	if query.Limit < 0 || query.Limit > constants.MaxLinksPageSize {
+		return nil, "", ErrInvalidQuery
+	}
+	filters := linksFiltersHash(query)
+	if cursor != "" {
+		after, err := decodeLinksCursor(cursor)
+		if err != nil || after.Short == "" {
+			return nil, "", ErrInvalidCursor
+		}
+		if after.SortBy != query.SortBy || after.Descending != query.Descending || after.Filters != filters {
+			return nil, "", ErrInvalidCursor
 		}
-		links = append(links, link)
+		query.After = &after
 	}
 
-	return links, nil
-}
-
-// DeleteLinks deletes a collection of short links in parallelized batches.
-func (s *UsersService) DeleteLinks(ctx context.Context, shortLinks []string) error {
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
-	defer cancel()
-
-	batches := setBatches(shortLinks)
-	batchChan := shortLinksGenerator(ctx, batches)
-	errChan := make(chan error, len(batches))
-
-	var wg sync.WaitGroup
-	for batch := range batchChan {
-		wg.Add(1)
-		data := batch
-		go func(batch []string) {
-			defer wg.Done()
-			err := s.usersRepository.DeleteLinks(ctx, batch)
-			if err != nil {
-				errChan <- fmt.Errorf("error updating the link patch: %w", err)
-			}
-		}(data)
+	limit := query.Limit
+	if limit > 0 {
+		query.Limit++
 	}
 
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
+	results, err := s.usersRepository.GetLinks(ctx, query)
+	if err != nil {
+		return nil, "", fmt.Errorf("links not found: %w", err)
+	}
 
-	var errs []error
-	for err := range errChan {
-		errs = append(errs, err)
+	var next string
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		next = encodeLinksCursor(models.LinksCursor{
+			SortBy:     query.SortBy,
+			Descending: query.Descending,
+			Filters:    filters,
+			Short:      last.Shorten,
+			CreatedAt:  last.CreatedAt,
+			Clicks:     last.Clicks,
+		})
 	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("the following errors occurred when updating the links: %v", errs)
+	links := make([]models.UserLinks, 0, len(results))
+	for _, result := range results {
+		result.Shorten = getResponseLink(result.Shorten, shortPre, constants.URLPrefix+host)
+		links = append(links, result)
 	}
 
-	return nil
+	return links, next, nil
 }
 
-// setBatches splits a large collection of links into smaller chunks for batch processing.
-func setBatches(shortLinks []string) [][]string {
-	var batches [][]string
-	for i := 0; i < len(shortLinks); i += batchSize {
-		end := i + batchSize
-		if end > len(shortLinks) {
-			end = len(shortLinks)
-		}
-		batch := shortLinks[i:end]
-		batches = append(batches, batch)
-	}
-	return batches
+// ExportLinks passes all links created by the current user, including soft-deleted ones, to yield one at a time
+// with their full short URLs. Unlike GetLinks, it is bounded only by the caller's context, since exports may be large.
+func (s *UsersService) ExportLinks(ctx context.Context, host string, yield func(models.ExportedLink) error) error {
+	return s.usersRepository.ExportLinks(ctx, func(link models.ExportedLink) error {
+		link.ShortURL = getResponseLink(link.Short, shortPre, constants.URLPrefix+host)
+		return yield(link)
+	})
 }
 
-// shortLinksGenerator feeds batches of links into a channel for consumption by worker goroutines.
-func shortLinksGenerator(ctx context.Context, batches [][]string) chan []string {
-	inputCh := make(chan []string, len(batches))
+// DeleteLinks queues the given links of the current user for background deletion.
+// Links that do not exist, belong to other users or are already deleted are skipped once the deletion is applied.
+func (s *UsersService) DeleteLinks(ctx context.Context, shortLinks []string) error {
+	userID, ok := ctx.Value(constants.UserIDKey).(int64)
+	if !ok {
+		return ErrUserNotFound
+	}
+	return s.deleter.Enqueue(userID, shortLinks)
+}
 
-	go func() {
-		defer close(inputCh)
+// RestoreLinks undoes the soft-deletion of the given links owned by the current user and returns how many were restored.
+// Links that are not deleted, belong to other users or have already been purged are skipped.
+func (s *UsersService) RestoreLinks(ctx context.Context, shortLinks []string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
 
-		for _, batch := range batches {
-			select {
-			case <-ctx.Done():
-				return
-			case inputCh <- batch:
-			}
-		}
-	}()
-	return inputCh
+	restored, err := s.usersRepository.RestoreLinks(ctx, shortLinks)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore links: %w", err)
+	}
+	return restored, nil
 }
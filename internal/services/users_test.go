@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"main/internal/constants"
+	"main/internal/mocks"
+	"main/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsersServiceGetLinksPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	filters := linksFiltersHash(models.LinksQuery{})
+	repo := mocks.NewMockUsersRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().GetLinks(gomock.Any(), models.LinksQuery{SortBy: constants.LinksSortCreated, Limit: 3}).
+			Return([]models.UserLinks{
+				{Shorten: "a", CreatedAt: createdAt},
+				{Shorten: "b", CreatedAt: createdAt},
+				{Shorten: "c", CreatedAt: createdAt},
+			}, nil),
+		repo.EXPECT().GetLinks(gomock.Any(), models.LinksQuery{
+			SortBy: constants.LinksSortCreated,
+			Limit:  3,
+			After:  &models.LinksCursor{SortBy: constants.LinksSortCreated, Filters: filters, Short: "b", CreatedAt: createdAt},
+		}).Return([]models.UserLinks{{Shorten: "c", CreatedAt: createdAt}}, nil),
+	)
+	s := NewUserService(repo, nil)
+
+	links, next, err := s.GetLinks(context.Background(), "localhost", models.LinksQuery{Limit: 2}, "")
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+	require.NotEmpty(t, next)
+
+	for name, query := range map[string]models.LinksQuery{
+		"sort key":      {Limit: 2, SortBy: constants.LinksSortClicks},
+		"direction":     {Limit: 2, Descending: true},
+		"origin":        {Limit: 2, Origin: "example"},
+		"created after": {Limit: 2, CreatedAfter: createdAt},
+		"deleted":       {Limit: 2, Deleted: true},
+	} {
+		_, _, err = s.GetLinks(context.Background(), "localhost", query, next)
+		assert.ErrorIs(t, err, ErrInvalidCursor, "cursor with another %s", name)
+	}
+
+	links, next, err = s.GetLinks(context.Background(), "localhost", models.LinksQuery{Limit: 2}, next)
+	require.NoError(t, err)
+	assert.Len(t, links, 1)
+	assert.Empty(t, next)
+
+	_, _, err = s.GetLinks(context.Background(), "localhost", models.LinksQuery{SortBy: constants.LinksSortClicks}, "bm90LWpzb24")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, _, err = s.GetLinks(context.Background(), "localhost", models.LinksQuery{SortBy: "name"}, "")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestUsersServiceAuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockUsersRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().GetUserByAPIKey(gomock.Any(), gomock.Any()).Return(int64(7), nil),
+		repo.EXPECT().GetUserByAPIKey(gomock.Any(), gomock.Any()).Return(int64(0), ErrAPIKeyNotFound),
+		repo.EXPECT().GetUserByAPIKey(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("connection refused")),
+	)
+	s := NewUserService(repo, nil)
+	key := constants.APIKeyPrefix + "secret"
+
+	userID, err := s.AuthenticateAPIKey(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), userID)
+
+	_, err = s.AuthenticateAPIKey(context.Background(), key)
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	_, err = s.AuthenticateAPIKey(context.Background(), key)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidAPIKey, "repository failures are not the key's fault")
+
+	_, err = s.AuthenticateAPIKey(context.Background(), "malformed")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+}